@@ -19,6 +19,7 @@ package main
 
 import (
 	"os"
+	"time"
 )
 
 import (
@@ -32,8 +33,12 @@ import (
 var (
 	Version = "0.1.0"
 
-	bootstrapConfigPath string
-	importBootConfPath  string
+	bootstrapConfigPath  string
+	importBootConfPath   string
+	exportBootConfPath   string
+	diffBootConfPath     string
+	historyBootConfPath  string
+	rollbackBootConfPath string
 )
 
 var (
@@ -49,6 +54,9 @@ func init() {
 	startCommand.
 		PersistentFlags().
 		StringVarP(&bootstrapConfigPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+	startCommand.
+		PersistentFlags().
+		DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "max time waiting for the in-flight statements when shutting down")
 
 	confImportCommand.
 		PersistentFlags().
@@ -56,9 +64,69 @@ func init() {
 	confImportCommand.
 		PersistentFlags().
 		StringVarP(&sourceConfigPath, constants.ImportConfigPathKey, "s", "", "import configuration file path")
+	confImportCommand.
+		PersistentFlags().
+		StringVar(&configAuthor, constants.ConfigAuthorKey, os.Getenv("USER"), "author of the configuration version")
+
+	confExportCommand.
+		PersistentFlags().
+		StringVarP(&exportBootConfPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+	confExportCommand.
+		PersistentFlags().
+		StringVarP(&exportConfigPath, constants.ExportConfigPathKey, "o", "", "export configuration file path, print to stdout if absent")
+
+	confDiffCommand.
+		PersistentFlags().
+		StringVarP(&diffBootConfPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+	confDiffCommand.
+		PersistentFlags().
+		StringVarP(&diffConfigPath, constants.ImportConfigPathKey, "s", "", "local configuration file path")
+
+	confHistoryCommand.
+		PersistentFlags().
+		StringVarP(&historyBootConfPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+
+	confRollbackCommand.
+		PersistentFlags().
+		StringVarP(&rollbackBootConfPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+	confRollbackCommand.
+		PersistentFlags().
+		Int64Var(&rollbackVersion, constants.ConfigVersionKey, 0, "the configuration version to rollback to")
+	confRollbackCommand.
+		PersistentFlags().
+		StringVar(&configAuthor, constants.ConfigAuthorKey, os.Getenv("USER"), "author of the configuration version")
+	_ = confRollbackCommand.MarkPersistentFlagRequired(constants.ConfigVersionKey)
+
+	encryptCommand.
+		PersistentFlags().
+		StringVar(&secretKey, "secret-key", os.Getenv(constants.EnvAranaSecretKey), "the key to encrypt secret")
+	encryptCommand.
+		PersistentFlags().
+		BoolVar(&secretHash, "hash", false, "output the mysql_native_password hash, which can be used as the password of front-end users")
+
+	validateCommand.
+		PersistentFlags().
+		StringVarP(&validateConfigPath, constants.ConfigPathKey, "c", "", "configuration file path")
+
+	routeCommand.
+		PersistentFlags().
+		StringVarP(&routeBootConfPath, constants.ConfigPathKey, "c", os.Getenv(constants.EnvAranaConfig), "bootstrap configuration file path")
+	routeCommand.
+		PersistentFlags().
+		StringVar(&routeSchema, "schema", "", "schema(cluster) of the sql, can be omitted if only one cluster exists")
+	routeCommand.
+		PersistentFlags().
+		StringSliceVarP(&routeArgs, "args", "a", nil, "arguments of placeholders in the sql")
 
 	rootCommand.AddCommand(startCommand)
 	rootCommand.AddCommand(confImportCommand)
+	rootCommand.AddCommand(confExportCommand)
+	rootCommand.AddCommand(confDiffCommand)
+	rootCommand.AddCommand(confHistoryCommand)
+	rootCommand.AddCommand(confRollbackCommand)
+	rootCommand.AddCommand(validateCommand)
+	rootCommand.AddCommand(encryptCommand)
+	rootCommand.AddCommand(routeCommand)
 }
 
 // Execute Execute command line analysis
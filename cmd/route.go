@@ -0,0 +1,349 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/boot"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/cmp"
+	"github.com/arana-db/arana/pkg/runtime/optimize"
+	utils "github.com/arana-db/arana/pkg/util/tableprint"
+)
+
+var (
+	routeBootConfPath string
+	routeSchema       string
+	routeArgs         []string
+)
+
+var (
+	routeCommand = &cobra.Command{
+		Use:     "route [sql]",
+		Short:   "simulate the sharding route of a sql",
+		Example: "./arana route -c ../docker/conf/bootstrap.yaml --schema employees \"select * from student where uid = 1\"",
+		Args:    cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if err := route(context.Background(), args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "route failed: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// routeShard represents a physical table and the rewritten sql on it.
+type routeShard struct {
+	db, table, sql string
+}
+
+func route(ctx context.Context, sql string) error {
+	provider := boot.NewProvider(routeBootConfPath)
+	if err := provider.Init(ctx); err != nil {
+		return errors.Wrap(err, "init failed")
+	}
+
+	schema := routeSchema
+	if len(schema) < 1 {
+		clusters, err := provider.ListClusters(ctx)
+		if err != nil {
+			return err
+		}
+		if len(clusters) != 1 {
+			return errors.Errorf("cannot determine schema from clusters %v, please specify --schema", clusters)
+		}
+		schema = clusters[0]
+	}
+
+	ru, err := boot.BuildRule(ctx, provider, schema)
+	if err != nil {
+		return errors.Wrapf(err, "build sharding rule of schema %s failed", schema)
+	}
+
+	stmt, err := ast.Parse(sql)
+	if err != nil {
+		return errors.Wrap(err, "parse sql failed")
+	}
+
+	args := make([]interface{}, 0, len(routeArgs))
+	for _, it := range routeArgs {
+		if n, err := strconv.ParseInt(it, 10, 64); err == nil {
+			args = append(args, n)
+		} else {
+			args = append(args, it)
+		}
+	}
+
+	res, err := routeStatement(ru, stmt, args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("schema: %s, table: %s\n", schema, res.table)
+	vt, ok := ru.VTable(res.table.Suffix())
+	if !ok {
+		fmt.Println("not a sharding table, route to the default group")
+		return nil
+	}
+	fmt.Printf("shards: %s\n", res.shards)
+	fmt.Printf("full scan: %v\n", res.fullScan)
+	if res.fullScan && !vt.AllowFullScan() {
+		fmt.Println("WARNING: full scan is not allowed on this table, the sql will be rejected")
+	}
+
+	rows := make([][]string, 0, len(res.routes))
+	for _, it := range res.routes {
+		rows = append(rows, []string{it.db, it.table, it.sql})
+	}
+	utils.WriteRows(os.Stdout, []string{"DATABASE", "TABLE", "SQL"}, rows)
+
+	return nil
+}
+
+// routeResult represents the route of a sql.
+type routeResult struct {
+	table    ast.TableName
+	shards   rule.DatabaseTables
+	fullScan bool
+	routes   []routeShard
+}
+
+// routeStatement computes the shards of statement, and renders the sql on each physical table.
+func routeStatement(ru *rule.Rule, stmt ast.Statement, args []interface{}) (*routeResult, error) {
+	var (
+		res routeResult
+		err error
+	)
+
+	switch st := stmt.(type) {
+	case *ast.SelectStatement:
+		if len(st.From) != 1 || st.From[0].TableName() == nil {
+			return nil, errors.New("only single table SELECT is supported")
+		}
+		res.table = st.From[0].TableName()
+		if res.shards, res.fullScan, err = computeRoute(ru, res.table, st.Where, args); err != nil {
+			return nil, err
+		}
+		res.routes, err = rewriteRoute(res.shards, func(tb string) ast.Statement {
+			from := *st.From[0]
+			from.ResetTableName(tb)
+			ret := *st
+			ret.From = append([]*ast.TableSourceNode{&from}, st.From[1:]...)
+			return &ret
+		})
+	case *ast.UpdateStatement:
+		res.table = st.Table
+		if res.shards, res.fullScan, err = computeRoute(ru, res.table, st.Where, args); err != nil {
+			return nil, err
+		}
+		res.routes, err = rewriteRoute(res.shards, func(tb string) ast.Statement {
+			return st.ResetTable(tb)
+		})
+	case *ast.DeleteStatement:
+		res.table = st.Table
+		if res.shards, res.fullScan, err = computeRoute(ru, res.table, st.Where, args); err != nil {
+			return nil, err
+		}
+		res.routes, err = rewriteRoute(res.shards, func(tb string) ast.Statement {
+			ret := *st
+			ret.Table = st.Table.ResetSuffix(tb)
+			return &ret
+		})
+	case *ast.TruncateStatement:
+		res.table = st.Table
+		if res.shards, res.fullScan, err = computeRoute(ru, res.table, nil, args); err != nil {
+			return nil, err
+		}
+		res.routes, err = rewriteRoute(res.shards, func(tb string) ast.Statement {
+			return st.ResetTable(tb)
+		})
+	case *ast.InsertStatement:
+		res.table = st.Table()
+		res.shards, res.routes, err = routeInsert(ru, st, args)
+	default:
+		return nil, errors.Errorf("unsupported statement type %T", stmt)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// computeRoute computes the shards, expands all physical tables if full scan.
+func computeRoute(ru *rule.Rule, table ast.TableName, where ast.ExpressionNode, args []interface{}) (rule.DatabaseTables, bool, error) {
+	vt, ok := ru.VTable(table.Suffix())
+	if !ok {
+		return nil, false, nil
+	}
+
+	shards, fullScan, err := (*optimize.Sharder)(ru).Shard(table, where, args...)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "calculate shards failed")
+	}
+
+	// nil shards means all tables, which are computed by topology
+	if shards != nil {
+		return shards, fullScan, nil
+	}
+
+	shards = rule.DatabaseTables{}
+	topology := vt.Topology()
+	topology.Each(func(dbIdx, tbIdx int) bool {
+		if d, t, ok := topology.Render(dbIdx, tbIdx); ok {
+			shards[d] = append(shards[d], t)
+		}
+		return true
+	})
+
+	return shards, fullScan, nil
+}
+
+// routeInsert computes the shard of each row, just like optimizing an INSERT statement.
+func routeInsert(ru *rule.Rule, stmt *ast.InsertStatement, args []interface{}) (rule.DatabaseTables, []routeShard, error) {
+	vt, ok := ru.VTable(stmt.Table().Suffix())
+	if !ok {
+		return nil, nil, nil
+	}
+
+	bingo := -1
+	for i, col := range stmt.Columns() {
+		if _, _, ok = vt.GetShardMetadata(col); ok {
+			bingo = i
+			break
+		}
+	}
+	if bingo < 0 {
+		return nil, nil, errors.New("no shard key found in INSERT statement")
+	}
+
+	var (
+		shards = make(rule.DatabaseTables)
+		slots  = make(map[string]map[string][]int) // (db,table,valuesIndex)
+	)
+
+	for i, values := range stmt.Values() {
+		value, ok := values[bingo].(*ast.PredicateExpressionNode)
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported value of shard key in row %d", i)
+		}
+		filter := &ast.PredicateExpressionNode{
+			P: &ast.BinaryComparisonPredicateNode{
+				Left: &ast.AtomPredicateNode{
+					A: ast.ColumnNameExpressionAtom{stmt.Columns()[bingo]},
+				},
+				Op:    cmp.Ceq,
+				Right: value.P,
+			},
+		}
+
+		ret, _, err := (*optimize.Sharder)(ru).Shard(stmt.Table(), filter, args...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "calculate shards failed")
+		}
+		if ret.Len() != 1 {
+			return nil, nil, errors.Errorf("cannot route row %d to a single table", i)
+		}
+
+		for db, tables := range ret {
+			if _, ok = slots[db]; !ok {
+				slots[db] = make(map[string][]int)
+			}
+			if _, ok = slots[db][tables[0]]; !ok {
+				shards[db] = append(shards[db], tables[0])
+			}
+			slots[db][tables[0]] = append(slots[db][tables[0]], i)
+		}
+	}
+
+	routes, err := rewriteRoute(shards, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range routes {
+		indexes := slots[routes[i].db][routes[i].table]
+		newborn := ast.NewInsertStatement(ast.TableName{routes[i].table}, stmt.Columns())
+		newborn.SetFlag(stmt.Flag())
+		newborn.SetDuplicatedUpdates(stmt.DuplicatedUpdates())
+
+		values := make([][]ast.ExpressionNode, 0, len(indexes))
+		for _, idx := range indexes {
+			values = append(values, stmt.Values()[idx])
+		}
+		newborn.SetValues(values)
+
+		if routes[i].sql, err = restore(newborn); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return shards, routes, nil
+}
+
+// rewriteRoute sorts the shards and renders the sql on each physical table.
+func rewriteRoute(shards rule.DatabaseTables, rewrite func(table string) ast.Statement) ([]routeShard, error) {
+	dbs := make([]string, 0, len(shards))
+	for db := range shards {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	var routes []routeShard
+	for _, db := range dbs {
+		tables := append([]string(nil), shards[db]...)
+		sort.Strings(tables)
+		for _, tb := range tables {
+			next := routeShard{db: db, table: tb}
+			if rewrite != nil {
+				var err error
+				if next.sql, err = restore(rewrite(tb)); err != nil {
+					return nil, err
+				}
+			}
+			routes = append(routes, next)
+		}
+	}
+	return routes, nil
+}
+
+func restore(stmt ast.Statement) (string, error) {
+	var (
+		sb   strings.Builder
+		args []int
+	)
+	if err := stmt.Restore(ast.RestoreDefault, &sb, &args); err != nil {
+		return "", errors.Wrap(err, "restore sql failed")
+	}
+	return sb.String(), nil
+}
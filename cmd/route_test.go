@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+)
+
+// makeRouteRule makes a rule with table 'student' sharded into 'student_0000' ~ 'student_0003' by 'uid % 4'.
+func makeRouteRule() *rule.Rule {
+	var (
+		ru   rule.Rule
+		tab  rule.VTable
+		topo rule.Topology
+	)
+
+	topo.SetRender(func(_ int) string {
+		return "fake_db"
+	}, func(i int) string {
+		return fmt.Sprintf("student_%04d", i)
+	})
+	topo.SetTopology(0, 0, 1, 2, 3)
+	tab.SetTopology(&topo)
+
+	var sm rule.ShardMetadata
+	sm.Steps = 4
+	sm.Computer = rule.DirectShardComputer(func(value interface{}) (int, error) {
+		n, err := strconv.Atoi(fmt.Sprintf("%v", value))
+		if err != nil {
+			return 0, err
+		}
+		return n % 4, nil
+	})
+	tab.SetShardMetadata("uid", nil, &sm)
+	ru.SetVTable("student", &tab)
+	return &ru
+}
+
+func TestRouteStatement(t *testing.T) {
+	type tt struct {
+		sql      string
+		args     []interface{}
+		fullScan bool
+		routes   []routeShard
+		err      bool
+	}
+
+	for _, it := range []tt{
+		{
+			sql:    "select * from student where uid = 1",
+			routes: []routeShard{{"fake_db", "student_0001", "SELECT * FROM `student_0001` WHERE `uid` = 1"}},
+		},
+		{
+			sql:    "select * from student where uid = ?",
+			args:   []interface{}{int64(6)},
+			routes: []routeShard{{"fake_db", "student_0002", "SELECT * FROM `student_0002` WHERE `uid` = ?"}},
+		},
+		{
+			sql:      "select * from student",
+			fullScan: true,
+			routes: []routeShard{
+				{"fake_db", "student_0000", "SELECT * FROM `student_0000`"},
+				{"fake_db", "student_0001", "SELECT * FROM `student_0001`"},
+				{"fake_db", "student_0002", "SELECT * FROM `student_0002`"},
+				{"fake_db", "student_0003", "SELECT * FROM `student_0003`"},
+			},
+		},
+		{
+			sql:    "delete from student where uid = 3",
+			routes: []routeShard{{"fake_db", "student_0003", "DELETE FROM `student_0003` WHERE `uid` = 3"}},
+		},
+		{
+			sql: "insert into student(uid, name) values (1, 'foo'), (5, 'bar'), (2, 'baz')",
+			routes: []routeShard{
+				{"fake_db", "student_0001", "INSERT INTO `student_0001`(`uid`, `name`) VALUES (1, 'foo'),(5, 'bar')"},
+				{"fake_db", "student_0002", "INSERT INTO `student_0002`(`uid`, `name`) VALUES (2, 'baz')"},
+			},
+		},
+		{sql: "insert into student(name) values ('foo')", err: true},
+		{sql: "insert into student(uid, name) values (1 or 2, 'foo')", err: true},
+		{sql: "select * from student a join student b on a.uid = b.uid", err: true},
+	} {
+		t.Run(it.sql, func(t *testing.T) {
+			stmt, err := ast.Parse(it.sql)
+			assert.NoError(t, err)
+
+			res, err := routeStatement(makeRouteRule(), stmt, it.args)
+			if it.err {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "student", res.table.Suffix())
+			assert.Equal(t, it.fullScan, res.fullScan)
+			assert.Equal(t, it.routes, res.routes)
+		})
+	}
+}
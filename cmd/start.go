@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 import (
@@ -31,11 +32,16 @@ import (
 )
 
 import (
+	"github.com/arana-db/arana/pkg/admin"
 	"github.com/arana-db/arana/pkg/boot"
+	"github.com/arana-db/arana/pkg/config"
 	"github.com/arana-db/arana/pkg/executor"
 	filter "github.com/arana-db/arana/pkg/filters"
 	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/server"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -51,12 +57,26 @@ var (
 				return
 			}
 
+			slowlog.Init(provider.GetBootOptions().SlowLog)
+
+			shutdownTrace, err := trace.Init(context.Background(), provider.GetBootOptions().Trace)
+			if err != nil {
+				log.Fatal("start failed: %v", err)
+				return
+			}
+			defer func() {
+				if err := shutdownTrace(context.Background()); err != nil {
+					log.Warnf("failed to shutdown tracing: %v", err)
+				}
+			}()
+
 			filters, err := provider.ListFilters(context.Background())
 			if err != nil {
 				log.Fatal("start failed: %v", err)
 				return
 			}
 
+			enabledFilters := make([]proto.Filter, 0, len(filters))
 			for _, filterConf := range filters {
 				factory := filter.GetFilterFactory(filterConf.Name)
 				if factory == nil {
@@ -67,6 +87,7 @@ var (
 					panic(errors.WithMessagef(err, "failed to create filter: %s", filterConf.Name))
 				}
 				filter.RegisterFilter(f.GetName(), f)
+				enabledFilters = append(enabledFilters, f)
 			}
 
 			propeller := server.NewServer()
@@ -78,30 +99,53 @@ var (
 			}
 
 			for _, listenerConf := range listenersConf {
-				listener, err := mysql.NewListener(listenerConf)
+				var protocolType config.ProtocolType
+				if err = protocolType.UnmarshalText([]byte(listenerConf.ProtocolType)); err != nil {
+					log.Fatalf("create listener failed: %v", err)
+					return
+				}
+
+				var listener proto.Listener
+				switch protocolType {
+				case config.Http:
+					listener, err = admin.NewListener(listenerConf, provider.GetConfigCenter())
+				default:
+					listener, err = mysql.NewListener(listenerConf)
+				}
 				if err != nil {
 					log.Fatalf("create listener failed: %v", err)
 					return
 				}
 				executor := executor.NewRedirectExecutor()
+				for _, f := range enabledFilters {
+					if pre, ok := f.(proto.PreFilter); ok {
+						executor.AddPreFilter(pre)
+					}
+					if post, ok := f.(proto.PostFilter); ok {
+						executor.AddPostFilter(post)
+					}
+				}
 				listener.SetExecutor(executor)
 				propeller.AddListener(listener)
 			}
 			propeller.Start()
 
-			ctx, cancel := context.WithCancel(context.Background())
 			c := make(chan os.Signal, 2)
 			signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+			<-c
+			log.Infof("shutting down gracefully in %s, send the signal again to exit immediately", shutdownTimeout)
 			go func() {
-				<-c
-				cancel()
 				<-c
 				os.Exit(1) // second signal. Exit directly.
 			}()
-			select {
-			case <-ctx.Done():
-				return
-			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			propeller.Shutdown(ctx)
+			log.Infof("arana is shut down")
 		},
 	}
 )
+
+// shutdownTimeout is the max time waiting for the in-flight statements when shutting down.
+var shutdownTimeout time.Duration
@@ -19,20 +19,37 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
 )
 
 import (
+	"github.com/ghodss/yaml"
+
+	"github.com/pmezard/go-difflib/difflib"
+
 	"github.com/spf13/cobra"
 )
 
 import (
 	"github.com/arana-db/arana/pkg/boot"
 	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/mysql"
 	"github.com/arana-db/arana/pkg/util/log"
+	utils "github.com/arana-db/arana/pkg/util/tableprint"
 )
 
 var (
 	sourceConfigPath string
+	exportConfigPath string
+	diffConfigPath   string
+	configAuthor     string
+	rollbackVersion  int64
+	secretKey        string
+	secretHash       bool
 )
 
 var (
@@ -55,10 +72,167 @@ var (
 
 			c := provider.GetConfigCenter()
 
-			if err := c.ImportConfiguration(cfg); err != nil {
+			if err := c.ImportConfigurationContext(config.WithAuthor(context.Background(), configAuthor), cfg); err != nil {
 				log.Fatal("persist config to config.store failed: %+v", err)
 				return
 			}
 		},
 	}
 )
+
+var (
+	confExportCommand = &cobra.Command{
+		Use:     "export",
+		Short:   "export arana config from config.store",
+		Example: "./arana export -c ../docker/conf/bootstrap.yaml -o ./config.yaml",
+		Run: func(*cobra.Command, []string) {
+			provider := boot.NewProvider(exportBootConfPath)
+			if err := provider.Init(context.Background()); err != nil {
+				log.Fatal("init failed: %+v", err)
+				return
+			}
+
+			cfg, err := provider.GetConfigCenter().LoadRaw()
+			if err != nil {
+				log.Fatal("load config from config.store failed: %+v", err)
+				return
+			}
+
+			if len(cfg.Kind) < 1 {
+				cfg.Kind = "Configuration"
+			}
+			if len(cfg.APIVersion) < 1 {
+				cfg.APIVersion = "1.0"
+			}
+
+			out, err := yaml.Marshal(cfg)
+			if err != nil {
+				log.Fatal("marshal config failed: %+v", err)
+				return
+			}
+
+			if len(exportConfigPath) < 1 {
+				_, _ = os.Stdout.Write(out)
+				return
+			}
+
+			if err = ioutil.WriteFile(exportConfigPath, out, 0644); err != nil {
+				log.Fatal("write config to %s failed: %+v", exportConfigPath, err)
+				return
+			}
+		},
+	}
+
+	confDiffCommand = &cobra.Command{
+		Use:     "diff",
+		Short:   "diff arana config between local file and config.store, exit with 1 if any difference found",
+		Example: "./arana diff -c ../docker/conf/bootstrap.yaml -s ../docker/conf/config.yaml",
+		Run: func(*cobra.Command, []string) {
+			provider := boot.NewProvider(diffBootConfPath)
+			if err := provider.Init(context.Background()); err != nil {
+				log.Fatal("init failed: %+v", err)
+				return
+			}
+
+			cfg, err := config.LoadV2(diffConfigPath)
+			if err != nil {
+				log.Fatal("load config from %s failed: %+v", diffConfigPath, err)
+				return
+			}
+
+			diffs, err := provider.GetConfigCenter().Diff(cfg)
+			if err != nil {
+				log.Fatal("diff config failed: %+v", err)
+				return
+			}
+
+			for _, it := range diffs {
+				text, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+					A:        difflib.SplitLines(it.Stored),
+					B:        difflib.SplitLines(it.Local),
+					FromFile: fmt.Sprintf("%s (stored)", it.Key),
+					ToFile:   fmt.Sprintf("%s (local)", it.Key),
+					Context:  3,
+				})
+				fmt.Println(text)
+			}
+
+			if len(diffs) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+var (
+	confHistoryCommand = &cobra.Command{
+		Use:     "history",
+		Short:   "list the versions of arana config in config.store",
+		Example: "./arana history -c ../docker/conf/bootstrap.yaml",
+		Run: func(*cobra.Command, []string) {
+			provider := boot.NewProvider(historyBootConfPath)
+			if err := provider.Init(context.Background()); err != nil {
+				log.Fatal("init failed: %+v", err)
+				return
+			}
+
+			versions, err := provider.GetConfigCenter().ListVersions()
+			if err != nil {
+				log.Fatal("list config versions failed: %+v", err)
+				return
+			}
+
+			rows := make([][]string, 0, len(versions))
+			for _, it := range versions {
+				rows = append(rows, []string{
+					strconv.FormatInt(it.Version, 10),
+					it.Timestamp.Format(time.RFC3339),
+					it.Author,
+					it.Checksum,
+				})
+			}
+			utils.WriteRows(os.Stdout, []string{"VERSION", "TIMESTAMP", "AUTHOR", "CHECKSUM"}, rows)
+		},
+	}
+
+	confRollbackCommand = &cobra.Command{
+		Use:     "rollback",
+		Short:   "rollback arana config in config.store to the given version",
+		Example: "./arana rollback -c ../docker/conf/bootstrap.yaml --version 3",
+		Run: func(*cobra.Command, []string) {
+			provider := boot.NewProvider(rollbackBootConfPath)
+			if err := provider.Init(context.Background()); err != nil {
+				log.Fatal("init failed: %+v", err)
+				return
+			}
+
+			ctx := config.WithAuthor(context.Background(), configAuthor)
+			if err := provider.GetConfigCenter().Rollback(ctx, rollbackVersion); err != nil {
+				log.Fatal("rollback config failed: %+v", err)
+				return
+			}
+		},
+	}
+)
+
+var (
+	encryptCommand = &cobra.Command{
+		Use:     "encrypt [plaintext]",
+		Short:   "encrypt a secret which can be used as the value of password in arana config",
+		Example: "./arana encrypt --secret-key foobar 123456",
+		Args:    cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if secretHash {
+				fmt.Println(mysql.NativePasswordHash(args[0]))
+				return
+			}
+
+			secret, err := config.NewSecretResolver(secretKey).Encrypt(args[0])
+			if err != nil {
+				log.Fatal("encrypt failed: %+v", err)
+				return
+			}
+			fmt.Println(secret)
+		},
+	}
+)
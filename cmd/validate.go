@@ -0,0 +1,58 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+import (
+	"github.com/spf13/cobra"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/boot"
+	"github.com/arana-db/arana/pkg/config"
+)
+
+var (
+	validateConfigPath string
+)
+
+var (
+	validateCommand = &cobra.Command{
+		Use:     "validate",
+		Short:   "validate arana config without starting",
+		Example: "./arana validate -c ../docker/conf/config.yaml",
+		Run: func(*cobra.Command, []string) {
+			cfg, err := config.LoadV2(validateConfigPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "load config from %s failed: %v\n", validateConfigPath, err)
+				os.Exit(1)
+			}
+
+			if err = boot.Validate(cfg); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+
+			fmt.Printf("configuration %s is valid\n", validateConfigPath)
+		},
+	}
+)
@@ -0,0 +1,218 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	_ "github.com/arana-db/arana/pkg/config/file"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	content, err := ioutil.ReadFile(testdata.Path("fake_config.yaml"))
+	assert.NoError(t, err)
+
+	center, err := config.NewCenter(config.ConfigOptions{
+		StoreName: "file",
+		Options:   map[string]interface{}{"content": string(content)},
+	})
+	assert.NoError(t, err)
+	defer center.Close()
+
+	db := testdata.NewMockDB(ctrl)
+	db.EXPECT().ID().Return("node_1").AnyTimes()
+	db.EXPECT().Weight().Return(proto.Weight{R: 10, W: 10}).AnyTimes()
+	db.EXPECT().Close().Return(nil).AnyTimes()
+	err = namespace.Register(namespace.New("employee", testdata.NewMockOptimizer(ctrl), namespace.UpsertDB("employee_0000", db)))
+	assert.NoError(t, err)
+	defer func() {
+		_ = namespace.Unregister("employee")
+	}()
+
+	var (
+		l       = &Listener{center: center, token: "fake_token"}
+		handler = l.routes()
+	)
+
+	call := func(method, path, body string) (int, string) {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer fake_token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := call(http.MethodGet, "/api/v1/namespaces", "")
+	assert.Equal(t, http.StatusOK, code)
+	var namespaces []*namespaceView
+	assert.NoError(t, json.Unmarshal([]byte(body), &namespaces))
+	assert.Len(t, namespaces, 1)
+	assert.Equal(t, "node_1", namespaces[0].Groups[0].Nodes[0].Name)
+
+	code, _ = call(http.MethodGet, "/api/v1/namespaces/fake_namespace", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, body = call(http.MethodGet, "/api/v1/namespaces/employee/rules", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "employee.student")
+
+	code, _ = call(http.MethodPut, "/api/v1/namespaces/employee/groups/employee_0000/nodes/node_1/weight", `{"weight":"r5w0"}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call(http.MethodPut, "/api/v1/namespaces/employee/groups/employee_0000/nodes/node_1/weight", `{"weight":"bad"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = call(http.MethodPut, "/api/v1/namespaces/employee/groups/employee_0000/nodes/fake_node/weight", `{"weight":"r5w0"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = call(http.MethodPut, "/api/v1/namespaces/employee/groups/employee_0001/nodes/node_2",
+		`{"host":"127.0.0.1","port":3306,"username":"root","password":"123456","database":"employees_0002","weight":"r10w10"}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	cfg, err := center.Load()
+	assert.NoError(t, err)
+	groups := cfg.Data.DataSourceClusters[0].Groups
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "r5w0", groups[0].Nodes[0].Weight)
+	assert.Equal(t, "node_2", groups[1].Nodes[0].Name)
+
+	code, _ = call(http.MethodDelete, "/api/v1/namespaces/employee/groups/employee_0001/nodes/node_2", "")
+	assert.Equal(t, http.StatusOK, code)
+	cfg, err = center.Load()
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Data.DataSourceClusters[0].Groups, 1)
+
+	code, _ = call(http.MethodPut, "/api/v1/tenants/arana/users/foo", `{"password":"*6BB4837EB74329105EE4568DDA7DC67ED2CA2AD9"}`)
+	assert.Equal(t, http.StatusOK, code)
+	code, body = call(http.MethodGet, "/api/v1/tenants", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"foo"`)
+	assert.NotContains(t, body, "6BB4837EB74329105EE4568DDA7DC67ED2CA2AD9")
+
+	code, _ = call(http.MethodDelete, "/api/v1/tenants/arana/users/foo", "")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call(http.MethodDelete, "/api/v1/tenants/arana/users/foo", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = call(http.MethodPost, "/api/v1/tenants", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	versions, err := center.ListVersions()
+	assert.NoError(t, err)
+	assert.Len(t, versions, 5)
+	assert.Equal(t, "admin", versions[0].Author)
+
+	code, body = call(http.MethodGet, "/metrics", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "go_goroutines")
+
+	// the last user of tenant used by clusters cannot be removed
+	code, body = call(http.MethodDelete, "/api/v1/tenants/arana/users/arana", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "used by cluster employee")
+	cfg, err = center.Load()
+	assert.NoError(t, err)
+	if assert.Len(t, cfg.Data.Tenants, 1) {
+		assert.Len(t, cfg.Data.Tenants[0].Users, 1)
+	}
+}
+
+func TestAdminAuthorize(t *testing.T) {
+	call := func(l *Listener, method, path, authorization string) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(`{"password":"123456"}`))
+		if len(authorization) > 0 {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		l.routes().ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// every request requires the token
+	l := &Listener{token: "fake_token"}
+	assert.Equal(t, http.StatusUnauthorized, call(l, http.MethodGet, "/metrics", ""))
+	assert.Equal(t, http.StatusUnauthorized, call(l, http.MethodGet, "/metrics", "Bearer wrong_token"))
+	assert.Equal(t, http.StatusUnauthorized, call(l, http.MethodPut, "/api/v1/tenants/arana/users/foo", "fake_token"))
+	assert.Equal(t, http.StatusOK, call(l, http.MethodGet, "/metrics", "Bearer fake_token"))
+
+	// the api is read-only without token
+	l = &Listener{}
+	assert.Equal(t, http.StatusOK, call(l, http.MethodGet, "/metrics", ""))
+	assert.Equal(t, http.StatusForbidden, call(l, http.MethodPut, "/api/v1/tenants/arana/users/foo", ""))
+	assert.Equal(t, http.StatusForbidden, call(l, http.MethodDelete, "/api/v1/tenants/arana/users/foo", "Bearer fake_token"))
+}
+
+func TestUpsertUser(t *testing.T) {
+	content, err := ioutil.ReadFile(testdata.Path("fake_config.yaml"))
+	assert.NoError(t, err)
+
+	center, err := config.NewCenter(config.ConfigOptions{
+		StoreName: "file",
+		Options:   map[string]interface{}{"content": string(content)},
+	})
+	assert.NoError(t, err)
+	defer center.Close()
+
+	err = center.Update(context.Background(), func(cfg *config.Configuration) error {
+		user := cfg.Data.Tenants[0].Users[0]
+		user.RequireTLS = true
+		user.CertSubject = "CN=arana"
+		user.Quota = &config.Quota{MaxConnections: 10}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	var (
+		l = &Listener{center: center, token: "fake_token"}
+		r = httptest.NewRequest(http.MethodPut, "/api/v1/tenants/arana/users/arana", strings.NewReader(`{"password":"654321"}`))
+		w = httptest.NewRecorder()
+	)
+	r.Header.Set("Authorization", "Bearer fake_token")
+	l.routes().ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// only the password is changed
+	cfg, err := center.Load()
+	assert.NoError(t, err)
+	users := cfg.Data.Tenants[0].Users
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "654321", users[0].Password)
+		assert.True(t, users[0].RequireTLS)
+		assert.Equal(t, "CN=arana", users[0].CertSubject)
+		assert.Equal(t, &config.Quota{MaxConnections: 10}, users[0].Quota)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package admin provides the HTTP admin API and prometheus metrics.
+// Every request must carry the configured admin token as a bearer token,
+// and the API which changes configuration is refused if no token is configured.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+var _ proto.Listener = (*Listener)(nil)
+
+// Listener serves the HTTP admin API.
+type Listener struct {
+	listener net.Listener
+	server   *http.Server
+	center   *config.Center
+	// token is the bearer token of requests, the API is read-only if empty.
+	token string
+}
+
+// NewListener creates a HTTP admin Listener, the changes will be persisted into config center.
+func NewListener(conf *config.Listener, center *config.Center) (proto.Listener, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.SocketAddress.Address, conf.SocketAddress.Port))
+	if err != nil {
+		log.Errorf("listen %s:%d error, %s", conf.SocketAddress.Address, conf.SocketAddress.Port, err)
+		return nil, err
+	}
+
+	listener := &Listener{
+		listener: l,
+		center:   center,
+		token:    conf.AdminToken,
+	}
+	if len(listener.token) < 1 {
+		log.Warnf("http admin Listener %s is read-only since admin_token is not configured", l.Addr())
+	}
+	listener.server = &http.Server{
+		Handler:      listener.routes(),
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return listener, nil
+}
+
+// SetExecutor does nothing, the admin API never executes sql.
+func (l *Listener) SetExecutor(proto.Executor) {
+}
+
+func (l *Listener) Listen() {
+	log.Infof("start http admin Listener %s", l.listener.Addr())
+	if err := l.server.Serve(l.listener); err != nil && err != http.ErrServerClosed {
+		log.Errorf("http admin Listener %s stopped: %v", l.listener.Addr(), err)
+	}
+}
+
+func (l *Listener) Close() {
+	if err := l.server.Close(); err != nil {
+		log.Warnf("failed to close http admin Listener %s: %v", l.listener.Addr(), err)
+	}
+}
+
+func (l *Listener) Shutdown(ctx context.Context) {
+	if err := l.server.Shutdown(ctx); err != nil {
+		log.Warnf("failed to shutdown http admin Listener %s: %v", l.listener.Addr(), err)
+		l.Close()
+	}
+}
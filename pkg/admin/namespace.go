@@ -0,0 +1,251 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/runtime"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+)
+
+type (
+	namespaceView struct {
+		Name   string       `json:"name"`
+		Groups []*groupView `json:"groups"`
+	}
+
+	groupView struct {
+		Name  string      `json:"name"`
+		Nodes []*nodeView `json:"nodes"`
+	}
+
+	nodeView struct {
+		Name   string             `json:"name"`
+		Weight weightView         `json:"weight"`
+		Stats  *runtime.PoolStats `json:"stats,omitempty"`
+	}
+
+	weightView struct {
+		R int32 `json:"r"`
+		W int32 `json:"w"`
+	}
+
+	weightRequest struct {
+		Weight string `json:"weight"` // eg: r10w10
+	}
+)
+
+func newNamespaceView(ns *namespace.Namespace) *namespaceView {
+	ret := &namespaceView{
+		Name:   ns.Name(),
+		Groups: make([]*groupView, 0),
+	}
+	for _, group := range ns.DBGroups() {
+		gv := &groupView{
+			Name:  group,
+			Nodes: make([]*nodeView, 0),
+		}
+		for _, db := range ns.DBs(group) {
+			weight := db.Weight()
+			nv := &nodeView{
+				Name:   db.ID(),
+				Weight: weightView{R: weight.R, W: weight.W},
+			}
+			if s, ok := db.(interface{ Stats() runtime.PoolStats }); ok {
+				stats := s.Stats()
+				nv.Stats = &stats
+			}
+			gv.Nodes = append(gv.Nodes, nv)
+		}
+		ret.Groups = append(ret.Groups, gv)
+	}
+	return ret
+}
+
+func (l *Listener) listNamespaces(w http.ResponseWriter, _ *http.Request, _ params) {
+	views := make([]*namespaceView, 0)
+	for _, name := range namespace.List() {
+		if ns := namespace.Load(name); ns != nil {
+			views = append(views, newNamespaceView(ns))
+		}
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (l *Listener) getNamespace(w http.ResponseWriter, _ *http.Request, ps params) {
+	ns := namespace.Load(ps["namespace"])
+	if ns == nil {
+		writeError(w, errNotFound("no such namespace %s", ps["namespace"]))
+		return
+	}
+	writeJSON(w, http.StatusOK, newNamespaceView(ns))
+}
+
+func (l *Listener) getRules(w http.ResponseWriter, _ *http.Request, ps params) {
+	cfg, err := l.center.LoadRaw()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if findCluster(cfg, ps["namespace"]) == nil {
+		writeError(w, errNotFound("no such namespace %s", ps["namespace"]))
+		return
+	}
+
+	tables := make([]*config.Table, 0)
+	if cfg.Data.ShardingRule != nil {
+		prefix := ps["namespace"] + "."
+		for _, it := range cfg.Data.ShardingRule.Tables {
+			if strings.HasPrefix(it.Name, prefix) {
+				tables = append(tables, it)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, tables)
+}
+
+func (l *Listener) updateWeight(w http.ResponseWriter, r *http.Request, ps params) {
+	var req weightRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err := l.update(r, func(cfg *config.Configuration) error {
+		_, node, err := findNode(cfg, ps["namespace"], ps["group"], ps["node"])
+		if err != nil {
+			return err
+		}
+		node.Weight = req.Weight
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func (l *Listener) upsertNode(w http.ResponseWriter, r *http.Request, ps params) {
+	var node config.Node
+	if err := readJSON(r, &node); err != nil {
+		writeError(w, err)
+		return
+	}
+	node.Name = ps["node"]
+
+	err := l.update(r, func(cfg *config.Configuration) error {
+		cluster := findCluster(cfg, ps["namespace"])
+		if cluster == nil {
+			return errNotFound("no such namespace %s", ps["namespace"])
+		}
+
+		var group *config.Group
+		for _, it := range cluster.Groups {
+			if it.Name == ps["group"] {
+				group = it
+				break
+			}
+		}
+		if group == nil {
+			group = &config.Group{Name: ps["group"]}
+			cluster.Groups = append(cluster.Groups, group)
+		}
+
+		for i, it := range group.Nodes {
+			if it.Name == node.Name {
+				group.Nodes[i] = &node
+				return nil
+			}
+		}
+		group.Nodes = append(group.Nodes, &node)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func (l *Listener) removeNode(w http.ResponseWriter, r *http.Request, ps params) {
+	err := l.update(r, func(cfg *config.Configuration) error {
+		group, _, err := findNode(cfg, ps["namespace"], ps["group"], ps["node"])
+		if err != nil {
+			return err
+		}
+
+		nodes := make([]*config.Node, 0, len(group.Nodes))
+		for _, it := range group.Nodes {
+			if it.Name != ps["node"] {
+				nodes = append(nodes, it)
+			}
+		}
+		group.Nodes = nodes
+
+		// remove the empty group
+		if len(nodes) < 1 {
+			cluster := findCluster(cfg, ps["namespace"])
+			groups := make([]*config.Group, 0, len(cluster.Groups))
+			for _, it := range cluster.Groups {
+				if it != group {
+					groups = append(groups, it)
+				}
+			}
+			cluster.Groups = groups
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func findCluster(cfg *config.Configuration, name string) *config.DataSourceCluster {
+	for _, it := range cfg.Data.DataSourceClusters {
+		if it.Name == name {
+			return it
+		}
+	}
+	return nil
+}
+
+func findNode(cfg *config.Configuration, cluster, group, node string) (*config.Group, *config.Node, error) {
+	c := findCluster(cfg, cluster)
+	if c == nil {
+		return nil, nil, errNotFound("no such namespace %s", cluster)
+	}
+	for _, g := range c.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, n := range g.Nodes {
+			if n.Name == node {
+				return g, n, nil
+			}
+		}
+	}
+	return nil, nil, errNotFound("no such node %s.%s.%s", cluster, group, node)
+}
@@ -0,0 +1,219 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/boot"
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+const (
+	_apiPrefix   = "/api/v1/"
+	_metricsPath = "/metrics"
+)
+
+// _authorHeader is the header to specify the author of configuration changes.
+const _authorHeader = "X-Arana-Author"
+
+type (
+	params map[string]string
+
+	handler func(w http.ResponseWriter, r *http.Request, ps params)
+
+	route struct {
+		method   string
+		segments []string // the segment starts with ':' is a parameter
+		handler  handler
+	}
+
+	// httpError represents an error with http status code.
+	httpError struct {
+		code int
+		msg  string
+	}
+)
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func errNotFound(format string, args ...interface{}) error {
+	return &httpError{code: http.StatusNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func errBadRequest(format string, args ...interface{}) error {
+	return &httpError{code: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *Listener) routes() http.Handler {
+	routes := []route{
+		{http.MethodGet, split("namespaces"), l.listNamespaces},
+		{http.MethodGet, split("namespaces/:namespace"), l.getNamespace},
+		{http.MethodGet, split("namespaces/:namespace/rules"), l.getRules},
+		{http.MethodPut, split("namespaces/:namespace/groups/:group/nodes/:node"), l.upsertNode},
+		{http.MethodDelete, split("namespaces/:namespace/groups/:group/nodes/:node"), l.removeNode},
+		{http.MethodPut, split("namespaces/:namespace/groups/:group/nodes/:node/weight"), l.updateWeight},
+		{http.MethodGet, split("tenants"), l.listTenants},
+		{http.MethodPut, split("tenants/:tenant/users/:user"), l.upsertUser},
+		{http.MethodDelete, split("tenants/:tenant/users/:user"), l.removeUser},
+	}
+
+	metrics := promhttp.Handler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := l.authorize(r); err != nil {
+			if he, ok := err.(*httpError); ok && he.code == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="arana"`)
+			}
+			writeError(w, err)
+			return
+		}
+
+		if r.URL.Path == _metricsPath {
+			metrics.ServeHTTP(w, r)
+			return
+		}
+
+		if !strings.HasPrefix(r.URL.Path, _apiPrefix) {
+			writeError(w, errNotFound("no such api %s", r.URL.Path))
+			return
+		}
+
+		segments := split(strings.TrimPrefix(r.URL.Path, _apiPrefix))
+		matched := false
+		for _, it := range routes {
+			ps, ok := it.match(segments)
+			if !ok {
+				continue
+			}
+			matched = true
+			if it.method == r.Method {
+				it.handler(w, r, ps)
+				return
+			}
+		}
+
+		if matched {
+			writeError(w, &httpError{code: http.StatusMethodNotAllowed, msg: "method not allowed"})
+			return
+		}
+		writeError(w, errNotFound("no such api %s", r.URL.Path))
+	})
+}
+
+// authorize checks the bearer token of request, the request which changes configuration
+// is refused if no token is configured.
+func (l *Listener) authorize(r *http.Request) error {
+	if len(l.token) < 1 {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return &httpError{code: http.StatusForbidden, msg: "admin_token is not configured, the admin api is read-only"}
+		}
+		return nil
+	}
+
+	authorization := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if len(token) == len(authorization) || subtle.ConstantTimeCompare([]byte(token), []byte(l.token)) != 1 {
+		return &httpError{code: http.StatusUnauthorized, msg: "invalid admin token"}
+	}
+	return nil
+}
+
+func (rt route) match(segments []string) (params, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	ps := make(params)
+	for i, it := range rt.segments {
+		if strings.HasPrefix(it, ":") {
+			ps[it[1:]] = segments[i]
+			continue
+		}
+		if it != segments[i] {
+			return nil, false
+		}
+	}
+	return ps, true
+}
+
+func split(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// update modifies the configuration, then persists it into config center.
+// The running namespaces and tenants will be reconciled after the configuration changed.
+func (l *Listener) update(r *http.Request, modify func(cfg *config.Configuration) error) error {
+	author := r.Header.Get(_authorHeader)
+	if len(author) < 1 {
+		author = "admin"
+	}
+
+	return l.center.Update(config.WithAuthor(r.Context(), author), func(cfg *config.Configuration) error {
+		if err := modify(cfg); err != nil {
+			return err
+		}
+		if err := boot.Validate(cfg); err != nil {
+			return errBadRequest("%v", err)
+		}
+		return nil
+	})
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errBadRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if v == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	var he *httpError
+	if errors.As(err, &he) {
+		code = he.code
+	} else {
+		log.Errorf("admin api failed: %+v", err)
+	}
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
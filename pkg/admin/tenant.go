@@ -0,0 +1,152 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"net/http"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+type (
+	tenantView struct {
+		Name     string   `json:"name"`
+		Users    []string `json:"users"`
+		Clusters []string `json:"clusters"`
+	}
+
+	userRequest struct {
+		// Password could be cleartext, mysql_native_password hash or secret reference.
+		Password string `json:"password"`
+	}
+)
+
+func (l *Listener) listTenants(w http.ResponseWriter, _ *http.Request, _ params) {
+	cfg, err := l.center.LoadRaw()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	views := make([]*tenantView, 0, len(cfg.Data.Tenants))
+	for _, tenant := range cfg.Data.Tenants {
+		view := &tenantView{
+			Name:     tenant.Name,
+			Users:    make([]string, 0, len(tenant.Users)),
+			Clusters: security.DefaultTenantManager().GetClusters(tenant.Name),
+		}
+		for _, it := range tenant.Users {
+			view.Users = append(view.Users, it.Username)
+		}
+		views = append(views, view)
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (l *Listener) upsertUser(w http.ResponseWriter, r *http.Request, ps params) {
+	var req userRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err := l.update(r, func(cfg *config.Configuration) error {
+		tenant := findTenant(cfg, ps["tenant"])
+		if tenant == nil {
+			tenant = &config.Tenant{Name: ps["tenant"]}
+			cfg.Data.Tenants = append(cfg.Data.Tenants, tenant)
+		}
+
+		// only the password is changed, the TLS requirements and quota of user are kept
+		for _, it := range tenant.Users {
+			if it.Username == ps["user"] {
+				it.Password = req.Password
+				return nil
+			}
+		}
+		tenant.Users = append(tenant.Users, &config.User{Username: ps["user"], Password: req.Password})
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func (l *Listener) removeUser(w http.ResponseWriter, r *http.Request, ps params) {
+	err := l.update(r, func(cfg *config.Configuration) error {
+		tenant := findTenant(cfg, ps["tenant"])
+		if tenant == nil {
+			return errNotFound("no such tenant %s", ps["tenant"])
+		}
+
+		users := make([]*config.User, 0, len(tenant.Users))
+		for _, it := range tenant.Users {
+			if it.Username != ps["user"] {
+				users = append(users, it)
+			}
+		}
+		if len(users) == len(tenant.Users) {
+			return errNotFound("no such user %s of tenant %s", ps["user"], ps["tenant"])
+		}
+		tenant.Users = users
+
+		// remove the empty tenant, the tenant used by clusters must keep at least one user
+		if len(users) < 1 {
+			if cluster := findClusterOfTenant(cfg, tenant.Name); len(cluster) > 0 {
+				return errBadRequest("cannot remove the last user of tenant %s, which is used by cluster %s", tenant.Name, cluster)
+			}
+			tenants := make([]*config.Tenant, 0, len(cfg.Data.Tenants))
+			for _, it := range cfg.Data.Tenants {
+				if it != tenant {
+					tenants = append(tenants, it)
+				}
+			}
+			cfg.Data.Tenants = tenants
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// findClusterOfTenant returns the name of first cluster used by tenant, or empty if not found.
+func findClusterOfTenant(cfg *config.Configuration, tenant string) string {
+	for _, it := range cfg.Data.DataSourceClusters {
+		if it.Tenant == tenant {
+			return it.Name
+		}
+	}
+	return ""
+}
+
+func findTenant(cfg *config.Configuration, name string) *config.Tenant {
+	for _, it := range cfg.Data.Tenants {
+		if it.Name == name {
+			return it
+		}
+	}
+	return nil
+}
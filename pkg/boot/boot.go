@@ -28,10 +28,12 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/config"
 	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/quota"
 	"github.com/arana-db/arana/pkg/runtime"
 	"github.com/arana-db/arana/pkg/runtime/namespace"
 	"github.com/arana-db/arana/pkg/runtime/optimize"
 	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/slowlog"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -40,6 +42,14 @@ func Boot(ctx context.Context, provider Discovery) error {
 		return err
 	}
 
+	cfg, err := provider.GetConfigCenter().LoadContext(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = Validate(cfg); err != nil {
+		return err
+	}
+
 	clusters, err := provider.ListClusters(ctx)
 	if err != nil {
 		return err
@@ -80,6 +90,16 @@ func Boot(ctx context.Context, provider Discovery) error {
 		for _, it := range t.Users {
 			security.DefaultTenantManager().PutUser(tenant, it)
 		}
+		security.DefaultTenantManager().SetRequireTLS(tenant, t.RequireTLS)
+		putAuthenticator(t)
+		putSlowLogThreshold(t)
+		putMaxExecutionTime(t)
+		putQuota(t)
+	}
+
+	// hot reload namespaces and tenants when the configuration changed
+	if err = provider.GetConfigCenter().Subscribe(newReconciler(provider, cfg).OnChange); err != nil {
+		return errors.Wrap(err, "failed to watch configuration")
 	}
 
 	return nil
@@ -110,8 +130,19 @@ func buildNamespace(ctx context.Context, provider Discovery, cluster string) (*n
 		}
 	}
 
-	var tables []string
-	if tables, err = provider.ListTables(ctx, cluster); err != nil {
+	var ru *rule.Rule
+	if ru, err = BuildRule(ctx, provider, cluster); err != nil {
+		return nil, err
+	}
+	initCmds = append(initCmds, namespace.UpdateRule(ru))
+
+	return namespace.New(cluster, optimize.GetOptimizer(), initCmds...), nil
+}
+
+// BuildRule builds the sharding rule of given cluster.
+func BuildRule(ctx context.Context, provider Discovery, cluster string) (*rule.Rule, error) {
+	tables, err := provider.ListTables(ctx, cluster)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -127,7 +158,38 @@ func buildNamespace(ctx context.Context, provider Discovery, cluster string) (*n
 		}
 		ru.SetVTable(table, vt)
 	}
-	initCmds = append(initCmds, namespace.UpdateRule(&ru))
+	return &ru, nil
+}
 
-	return namespace.New(cluster, optimize.GetOptimizer(), initCmds...), nil
+func putSlowLogThreshold(tenant *config.Tenant) {
+	threshold, err := tenant.GetSlowLogThreshold()
+	if err != nil {
+		log.Errorf("failed to set slow log threshold of tenant %s: %v", tenant.Name, err)
+		return
+	}
+	slowlog.SetThreshold(tenant.Name, threshold)
+}
+
+func putMaxExecutionTime(tenant *config.Tenant) {
+	timeout, err := tenant.GetMaxExecutionTime()
+	if err != nil {
+		log.Errorf("failed to set max execution time of tenant %s: %v", tenant.Name, err)
+		return
+	}
+	security.DefaultTenantManager().SetMaxExecutionTime(tenant.Name, timeout)
+}
+
+func putQuota(tenant *config.Tenant) {
+	if err := quota.PutTenant(tenant); err != nil {
+		log.Errorf("failed to set quota of tenant %s: %v", tenant.Name, err)
+	}
+}
+
+func putAuthenticator(tenant *config.Tenant) {
+	auth, err := security.NewAuthenticator(tenant.Authenticator)
+	if err != nil {
+		log.Errorf("failed to set authenticator of tenant %s: %v", tenant.Name, err)
+		return
+	}
+	security.DefaultTenantManager().SetAuthenticator(tenant.Name, auth)
 }
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -37,6 +38,7 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	rrule "github.com/arana-db/arana/pkg/runtime/rule"
 	"github.com/arana-db/arana/pkg/util/file"
@@ -106,6 +108,8 @@ type Discovery interface {
 
 	// GetConfigCenter
 	GetConfigCenter() *config.Center
+	// GetBootOptions returns the bootstrap options.
+	GetBootOptions() *BootOptions
 }
 
 type discovery struct {
@@ -149,6 +153,10 @@ func (fp *discovery) loadBootOptions() error {
 }
 
 func (fp *discovery) initConfigCenter() error {
+	if len(fp.options.Config.SecretKey) < 1 {
+		fp.options.Config.SecretKey = os.Getenv(constants.EnvAranaSecretKey)
+	}
+
 	c, err := config.NewCenter(*fp.options.Config)
 	if err != nil {
 		return err
@@ -163,6 +171,10 @@ func (fp *discovery) GetConfigCenter() *config.Center {
 	return fp.c
 }
 
+func (fp *discovery) GetBootOptions() *BootOptions {
+	return fp.options
+}
+
 func (fp *discovery) GetCluster(ctx context.Context, cluster string) (*Cluster, error) {
 	exist, ok := fp.loadCluster(cluster)
 	if !ok {
@@ -299,8 +311,17 @@ func (fp *discovery) GetTable(ctx context.Context, cluster, tableName string) (*
 	if !ok {
 		return nil, nil
 	}
-	var vt rule.VTable
 
+	return toVTable(table)
+}
+
+// toVTable builds the virtual table from the table config.
+func toVTable(table *config.Table) (*rule.VTable, error) {
+	var (
+		vt  rule.VTable
+		ok  bool
+		err error
+	)
 	var (
 		topology           rule.Topology
 		dbFormat, tbFormat string
@@ -379,6 +400,9 @@ func (fp *discovery) GetTable(ctx context.Context, cluster, tableName string) (*
 				tbMetadata.Steps = 1 + tbEnd - tbBegin
 			}
 		}
+		if tbMetadata == nil {
+			return nil, errors.Errorf("no table shard rule found for column %s", k)
+		}
 		vt.SetShardMetadata(k, dbMetadata, tbMetadata)
 
 		tpRes := make(map[int][]int)
@@ -411,6 +435,14 @@ func (fp *discovery) GetTable(ctx context.Context, cluster, tableName string) (*
 		vt.SetAllowFullScan(true)
 	}
 
+	timeout, err := table.GetMaxExecutionTime()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if timeout > 0 {
+		vt.SetMaxExecutionTime(timeout)
+	}
+
 	// TODO: process attributes
 	_ = table.Attributes["sql_max_limit"]
 
@@ -19,8 +19,12 @@ package boot
 
 import (
 	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 type BootOptions struct {
-	Config *config.ConfigOptions `yaml:"config"`
+	Config  *config.ConfigOptions `yaml:"config"`
+	Trace   *trace.Options        `yaml:"trace"`
+	SlowLog *slowlog.Options      `yaml:"slow_log"`
 }
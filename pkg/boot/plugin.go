@@ -21,4 +21,6 @@ import (
 	_ "github.com/arana-db/arana/pkg/config/etcd"
 	_ "github.com/arana-db/arana/pkg/config/file"
 	_ "github.com/arana-db/arana/pkg/config/nacos"
+	_ "github.com/arana-db/arana/pkg/filters/audit"
+	_ "github.com/arana-db/arana/pkg/filters/firewall"
 )
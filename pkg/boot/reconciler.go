@@ -0,0 +1,324 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/quota"
+	"github.com/arana-db/arana/pkg/runtime"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+// reconciler diffs the latest configuration against the running state,
+// and enqueues the minimal commands into namespaces.
+type reconciler struct {
+	sync.Mutex
+	provider Discovery
+	current  *config.Configuration
+	newDB    func(node *config.Node) proto.DB
+}
+
+func newReconciler(provider Discovery, current *config.Configuration) *reconciler {
+	return &reconciler{
+		provider: provider,
+		current:  current,
+		newDB: func(node *config.Node) proto.DB {
+			if db := runtime.NewAtomDB(node); db != nil {
+				return db
+			}
+			return nil
+		},
+	}
+}
+
+// OnChange reloads the configuration from config center and applies the differences.
+func (r *reconciler) OnChange() {
+	next, err := r.provider.GetConfigCenter().Load()
+	if err != nil {
+		log.Errorf("failed to load configuration: %v", err)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if next == r.current {
+		return
+	}
+
+	if err = Validate(next); err != nil {
+		log.Errorf("skip reconciling: %v", err)
+		return
+	}
+
+	r.reconcile(context.Background(), r.current, next)
+	r.current = next
+}
+
+func (r *reconciler) reconcile(ctx context.Context, prev, next *config.Configuration) {
+	var (
+		prevClusters = indexClusters(prev)
+		nextClusters = indexClusters(next)
+		prevTables   = indexTables(prev)
+		nextTables   = indexTables(next)
+	)
+
+	for name, cluster := range prevClusters {
+		if _, ok := nextClusters[name]; ok {
+			continue
+		}
+		if err := namespace.Unregister(name); err != nil {
+			log.Errorf("unregister namespace %s failed: %v", name, err)
+		}
+		security.DefaultTenantManager().RemoveCluster(cluster.Tenant, name)
+		log.Infof("unregister namespace %s successfully", name)
+	}
+
+	for name, cluster := range nextClusters {
+		exist, ok := prevClusters[name]
+		if !ok {
+			if err := r.addCluster(ctx, cluster); err != nil {
+				log.Errorf("register namespace %s failed: %v", name, err)
+			}
+			continue
+		}
+
+		ns := namespace.Load(name)
+		if ns == nil {
+			log.Warnf("no such namespace %s, skip reconciling", name)
+			continue
+		}
+
+		if exist.Tenant != cluster.Tenant {
+			security.DefaultTenantManager().RemoveCluster(exist.Tenant, name)
+			security.DefaultTenantManager().PutCluster(cluster.Tenant, name)
+		}
+
+		for _, cmd := range r.diffGroups(exist, cluster) {
+			if err := ns.EnqueueCommand(cmd); err != nil {
+				log.Errorf("[%s] enqueue command failed: %v", name, err)
+			}
+		}
+
+		if reflect.DeepEqual(prevTables[name], nextTables[name]) {
+			continue
+		}
+		ru, err := BuildRule(ctx, r.provider, name)
+		if err != nil {
+			log.Errorf("[%s] build sharding rule failed: %v", name, err)
+			continue
+		}
+		if err = ns.EnqueueCommand(namespace.UpdateRule(ru)); err != nil {
+			log.Errorf("[%s] enqueue command failed: %v", name, err)
+		}
+	}
+
+	r.reconcileTenants(prev, next)
+}
+
+func (r *reconciler) addCluster(ctx context.Context, cluster *config.DataSourceCluster) error {
+	ns, err := buildNamespace(ctx, r.provider, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if err = namespace.Register(ns); err != nil {
+		return err
+	}
+	security.DefaultTenantManager().PutCluster(cluster.Tenant, cluster.Name)
+	log.Infof("register namespace %s successfully", cluster.Name)
+	return nil
+}
+
+// diffGroups computes the commands which turn the nodes of prev into next.
+func (r *reconciler) diffGroups(prev, next *config.DataSourceCluster) []namespace.Command {
+	var (
+		cmds      []namespace.Command
+		prevNodes = indexNodes(prev)
+		nextNodes = indexNodes(next)
+	)
+
+	for group, nodes := range prevNodes {
+		for name := range nodes {
+			if _, ok := nextNodes[group][name]; !ok {
+				cmds = append(cmds, namespace.RemoveDB(group, name))
+			}
+		}
+	}
+
+	for group, nodes := range nextNodes {
+		for name, node := range nodes {
+			exist, ok := prevNodes[group][name]
+			if ok && reflect.DeepEqual(exist, node) {
+				continue
+			}
+
+			if ok && onlyWeightChanged(exist, node) {
+				rw, ww, err := node.GetReadAndWriteWeight()
+				if err != nil {
+					log.Errorf("[%s] invalid weight of node %s.%s: %v", next.Name, group, name, err)
+					continue
+				}
+				cmds = append(cmds, namespace.UpdateWeight(group, name, proto.Weight{R: int32(rw), W: int32(ww)}))
+				continue
+			}
+
+			db := r.newDB(node)
+			if db == nil {
+				log.Errorf("[%s] cannot create db of node %s.%s", next.Name, group, name)
+				continue
+			}
+			cmds = append(cmds, namespace.UpsertDB(group, db))
+		}
+	}
+
+	return cmds
+}
+
+func (r *reconciler) reconcileTenants(prev, next *config.Configuration) {
+	var (
+		tm          = security.DefaultTenantManager()
+		prevTenants = indexTenants(prev)
+		nextTenants = indexTenants(next)
+	)
+
+	for tenant, users := range prevTenants {
+		for username := range users {
+			if _, ok := nextTenants[tenant][username]; !ok {
+				tm.RemoveUser(tenant, username)
+				mysql.ForgetSha2Hash(tenant, username)
+				log.Infof("remove user %s of tenant %s successfully", username, tenant)
+			}
+		}
+	}
+
+	for tenant, users := range nextTenants {
+		for username, user := range users {
+			if exist, ok := prevTenants[tenant][username]; ok && reflect.DeepEqual(exist, user) {
+				continue
+			}
+			tm.PutUser(tenant, user)
+			mysql.ForgetSha2Hash(tenant, username)
+			log.Infof("put user %s of tenant %s successfully", username, tenant)
+		}
+	}
+
+	for tenant := range prevTenants {
+		if _, ok := nextTenants[tenant]; !ok {
+			slowlog.SetThreshold(tenant, 0)
+			quota.RemoveTenant(tenant)
+			tm.RemoveTenant(tenant)
+			mysql.ForgetSha2Hash(tenant, "")
+			log.Infof("remove tenant %s successfully", tenant)
+		}
+	}
+	if next != nil && next.Data != nil {
+		for _, it := range next.Data.Tenants {
+			tm.SetRequireTLS(it.Name, it.RequireTLS)
+			if !reflect.DeepEqual(prevAuthenticator(prev, it.Name), it.Authenticator) {
+				putAuthenticator(it)
+			}
+			putSlowLogThreshold(it)
+			putMaxExecutionTime(it)
+			putQuota(it)
+		}
+	}
+}
+
+// prevAuthenticator returns the authenticator config of tenant in previous configuration.
+func prevAuthenticator(prev *config.Configuration, tenant string) *config.Authenticator {
+	if prev == nil || prev.Data == nil {
+		return nil
+	}
+	for _, it := range prev.Data.Tenants {
+		if it.Name == tenant {
+			return it.Authenticator
+		}
+	}
+	return nil
+}
+
+func onlyWeightChanged(prev, next *config.Node) bool {
+	a, b := *prev, *next
+	a.Weight, b.Weight = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+func indexClusters(cfg *config.Configuration) map[string]*config.DataSourceCluster {
+	clusters := make(map[string]*config.DataSourceCluster)
+	if cfg == nil || cfg.Data == nil {
+		return clusters
+	}
+	for _, it := range cfg.Data.DataSourceClusters {
+		clusters[it.Name] = it
+	}
+	return clusters
+}
+
+func indexNodes(cluster *config.DataSourceCluster) map[string]map[string]*config.Node {
+	nodes := make(map[string]map[string]*config.Node)
+	for _, group := range cluster.Groups {
+		if _, ok := nodes[group.Name]; !ok {
+			nodes[group.Name] = make(map[string]*config.Node)
+		}
+		for _, it := range group.Nodes {
+			nodes[group.Name][it.Name] = it
+		}
+	}
+	return nodes
+}
+
+func indexTables(cfg *config.Configuration) map[string][]*config.Table {
+	tables := make(map[string][]*config.Table)
+	if cfg == nil || cfg.Data == nil || cfg.Data.ShardingRule == nil {
+		return tables
+	}
+	for _, it := range cfg.Data.ShardingRule.Tables {
+		db, _, err := parseTable(it.Name)
+		if err != nil {
+			continue
+		}
+		tables[db] = append(tables[db], it)
+	}
+	return tables
+}
+
+func indexTenants(cfg *config.Configuration) map[string]map[string]*config.User {
+	tenants := make(map[string]map[string]*config.User)
+	if cfg == nil || cfg.Data == nil {
+		return tenants
+	}
+	for _, tenant := range cfg.Data.Tenants {
+		users := make(map[string]*config.User)
+		for _, it := range tenant.Users {
+			users[it.Username] = it
+		}
+		tenants[tenant.Name] = users
+	}
+	return tenants
+}
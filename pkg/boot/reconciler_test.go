@@ -0,0 +1,174 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/quota"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const cluster = "fake_reconcile"
+
+	var (
+		weight = proto.Weight{R: 10, W: 10}
+		dbs    = make(map[string]*testdata.MockDB)
+	)
+
+	newDB := func(node *config.Node) proto.DB {
+		db := testdata.NewMockDB(ctrl)
+		db.EXPECT().ID().Return(node.Name).AnyTimes()
+		db.EXPECT().Weight().DoAndReturn(func() proto.Weight { return weight }).AnyTimes()
+		db.EXPECT().Close().Return(nil).AnyTimes()
+		db.EXPECT().SetWeight(gomock.Any()).DoAndReturn(func(w proto.Weight) error {
+			weight = w
+			return nil
+		}).AnyTimes()
+		dbs[node.Name] = db
+		return db
+	}
+
+	prev := &config.Configuration{
+		Data: &config.Data{
+			Tenants: []*config.Tenant{
+				{Name: "fake_tenant", Users: []*config.User{{Username: "foo", Password: "foo"}}},
+			},
+			DataSourceClusters: []*config.DataSourceCluster{
+				{
+					Name:   cluster,
+					Tenant: "fake_tenant",
+					Groups: []*config.Group{
+						{
+							Name: "group_0",
+							Nodes: []*config.Node{
+								{Name: "node_0", Host: "127.0.0.1", Port: 3306, Weight: "r10w10"},
+								{Name: "node_1", Host: "127.0.0.2", Port: 3306, Weight: "r10w10"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	next, err := prev.Clone()
+	assert.NoError(t, err)
+	next.Data.DataSourceClusters[0].Groups[0].Nodes[0].Weight = "r5w0"
+	next.Data.DataSourceClusters[0].Groups[0].Nodes = next.Data.DataSourceClusters[0].Groups[0].Nodes[:1]
+	next.Data.DataSourceClusters[0].Groups = append(next.Data.DataSourceClusters[0].Groups, &config.Group{
+		Name:  "group_1",
+		Nodes: []*config.Node{{Name: "node_2", Host: "127.0.0.3", Port: 3306, Weight: "r10w10"}},
+	})
+	next.Data.Tenants[0].Users = []*config.User{{Username: "bar", Password: "bar"}}
+
+	var initCmds []namespace.Command
+	for _, group := range prev.Data.DataSourceClusters[0].Groups {
+		for _, node := range group.Nodes {
+			initCmds = append(initCmds, namespace.UpsertDB(group.Name, newDB(node)))
+		}
+	}
+	err = namespace.Register(namespace.New(cluster, testdata.NewMockOptimizer(ctrl), initCmds...))
+	assert.NoError(t, err)
+	defer func() {
+		_ = namespace.Unregister(cluster)
+	}()
+	security.DefaultTenantManager().PutUser("fake_tenant", prev.Data.Tenants[0].Users[0])
+
+	r := newReconciler(nil, prev)
+	r.newDB = newDB
+	r.reconcile(context.Background(), prev, next)
+
+	time.Sleep(10 * time.Millisecond)
+
+	ns := namespace.Load(cluster)
+	assert.Equal(t, []string{"group_0", "group_1"}, ns.DBGroups())
+	assert.Equal(t, "node_0", ns.DB(context.Background(), "group_0").ID())
+	assert.Equal(t, "node_2", ns.DB(context.Background(), "group_1").ID())
+	assert.Equal(t, proto.Weight{R: 5, W: 0}, weight)
+
+	_, ok := security.DefaultTenantManager().GetUser("fake_tenant", "foo")
+	assert.False(t, ok)
+	_, ok = security.DefaultTenantManager().GetUser("fake_tenant", "bar")
+	assert.True(t, ok)
+}
+
+func TestReconcileRemoveTenant(t *testing.T) {
+	const tenant = "fake_removed_tenant"
+
+	prev := &config.Configuration{
+		Data: &config.Data{
+			Tenants: []*config.Tenant{
+				{
+					Name:             tenant,
+					Users:            []*config.User{{Username: "foo", Password: "foo", Quota: &config.Quota{QPS: 10}}},
+					SlowLogThreshold: "1s",
+					MaxExecutionTime: "10s",
+					Quota:            &config.Quota{MaxConnections: 10},
+					RequireTLS:       true,
+				},
+			},
+		},
+	}
+	next := &config.Configuration{Data: &config.Data{}}
+
+	r := newReconciler(nil, nil)
+	r.reconcile(context.Background(), nil, prev)
+
+	tm := security.DefaultTenantManager()
+	_, ok := tm.GetUser(tenant, "foo")
+	assert.True(t, ok)
+	assert.True(t, tm.IsRequireTLS(tenant))
+	_, ok = quota.Get(tenant, "foo")
+	assert.True(t, ok)
+	_, ok = slowlog.Threshold(tenant)
+	assert.True(t, ok)
+
+	// the users, settings and limiters of removed tenant are released
+	r.reconcile(context.Background(), prev, next)
+
+	_, ok = tm.GetUser(tenant, "foo")
+	assert.False(t, ok)
+	assert.False(t, tm.IsRequireTLS(tenant))
+	assert.Zero(t, tm.GetMaxExecutionTime(tenant))
+	_, ok = quota.Get(tenant, "")
+	assert.False(t, ok)
+	_, ok = quota.Get(tenant, "foo")
+	assert.False(t, ok)
+	_, ok = slowlog.Threshold(tenant)
+	assert.False(t, ok)
+}
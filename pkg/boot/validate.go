@@ -0,0 +1,377 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+// ValidationError represents an invalid item of configuration.
+type ValidationError struct {
+	Path    string // the YAML path, eg: data.clusters[0].groups[1].nodes[0].weight
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors represents all invalid items of configuration.
+type ValidationErrors []*ValidationError
+
+func (ve ValidationErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid configuration:")
+	for _, it := range ve {
+		sb.WriteString("\n  ")
+		sb.WriteString(it.Error())
+	}
+	return sb.String()
+}
+
+// Validate validates the configuration, returns ValidationErrors if any invalid item found.
+func Validate(cfg *config.Configuration) error {
+	var v validator
+	v.validate(cfg)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) addError(path string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(cfg *config.Configuration) {
+	if cfg == nil {
+		v.addError("", "configuration is empty")
+		return
+	}
+
+	v.validateRequired("", reflect.ValueOf(cfg))
+
+	if cfg.Data == nil {
+		return
+	}
+
+	for i, it := range cfg.Data.Listeners {
+		v.validateListener(fmt.Sprintf("data.listeners[%d]", i), it)
+	}
+
+	tenants := make(map[string]struct{})
+	for i, it := range cfg.Data.Tenants {
+		if it == nil {
+			continue
+		}
+		path := fmt.Sprintf("data.tenants[%d]", i)
+		if _, ok := tenants[it.Name]; ok {
+			v.addError(path+".name", "duplicated tenant '%s'", it.Name)
+		}
+		tenants[it.Name] = struct{}{}
+		if _, err := it.GetSlowLogThreshold(); err != nil {
+			v.addError(path+".slow_log_threshold", "%v", err)
+		}
+		if _, err := it.GetMaxExecutionTime(); err != nil {
+			v.addError(path+".max_execution_time", "%v", err)
+		}
+		v.validateQuota(path+".quota", it.Quota)
+		if it.Authenticator != nil {
+			if _, err := security.NewAuthenticator(it.Authenticator); err != nil {
+				v.addError(path+".authenticator", "%v", err)
+			}
+		}
+		for j, user := range it.Users {
+			if user != nil {
+				v.validateQuota(fmt.Sprintf("%s.users[%d].quota", path, j), user.Quota)
+			}
+		}
+	}
+
+	clusters := make(map[string]*config.DataSourceCluster)
+	for i, it := range cfg.Data.DataSourceClusters {
+		if it == nil {
+			continue
+		}
+		path := fmt.Sprintf("data.clusters[%d]", i)
+		if _, ok := clusters[it.Name]; ok {
+			v.addError(path+".name", "duplicated cluster '%s'", it.Name)
+		}
+		clusters[it.Name] = it
+		if _, ok := tenants[it.Tenant]; !ok && len(it.Tenant) > 0 {
+			v.addError(path+".tenant", "no such tenant '%s'", it.Tenant)
+		}
+		v.validateCluster(path, it)
+	}
+
+	if cfg.Data.ShardingRule != nil {
+		for i, it := range cfg.Data.ShardingRule.Tables {
+			if it == nil {
+				continue
+			}
+			v.validateTable(fmt.Sprintf("data.sharding_rule.tables[%d]", i), it, clusters)
+		}
+	}
+}
+
+func (v *validator) validateListener(path string, listener *config.Listener) {
+	if listener == nil {
+		return
+	}
+	if len(listener.ProtocolType) > 0 {
+		var pt config.ProtocolType
+		if err := pt.UnmarshalText([]byte(listener.ProtocolType)); err != nil {
+			v.addError(path+".protocol_type", "%v", err)
+		}
+	}
+	if addr := listener.SocketAddress; addr != nil && (addr.Port <= 0 || addr.Port > 65535) {
+		v.addError(path+".socket_address.port", "invalid port %d", addr.Port)
+	}
+	switch listener.DefaultAuthPlugin {
+	case "", mysql.MysqlNativePassword, mysql.CachingSha2Password, mysql.Sha256Password:
+	default:
+		v.addError(path+".default_auth_plugin", "unsupported auth plugin '%s'", listener.DefaultAuthPlugin)
+	}
+	if t := listener.TLS; t != nil {
+		if len(t.CertFile) < 1 || len(t.KeyFile) < 1 {
+			v.addError(path+".tls", "both cert_file and key_file are required")
+		}
+		if t.RequireClientCert && len(t.CAFile) < 1 {
+			v.addError(path+".tls.ca_file", "ca_file is required to verify client certificates")
+		}
+	}
+}
+
+func (v *validator) validateQuota(path string, quota *config.Quota) {
+	if quota == nil {
+		return
+	}
+	if quota.QPS < 0 || quota.MaxConcurrency < 0 || quota.MaxConnections < 0 || quota.QueueSize < 0 {
+		v.addError(path, "negative limits are not allowed")
+	}
+	if _, err := quota.GetQueueTimeout(); err != nil {
+		v.addError(path+".queue_timeout", "%v", err)
+	}
+}
+
+func (v *validator) validateCluster(path string, cluster *config.DataSourceCluster) {
+	groups := make(map[string]struct{})
+	for i, group := range cluster.Groups {
+		if group == nil {
+			continue
+		}
+		groupPath := fmt.Sprintf("%s.groups[%d]", path, i)
+		if _, ok := groups[group.Name]; ok {
+			v.addError(groupPath+".name", "duplicated group '%s'", group.Name)
+		}
+		groups[group.Name] = struct{}{}
+
+		nodes := make(map[string]struct{})
+		for j, node := range group.Nodes {
+			if node == nil {
+				continue
+			}
+			nodePath := fmt.Sprintf("%s.nodes[%d]", groupPath, j)
+			if _, ok := nodes[node.Name]; ok {
+				v.addError(nodePath+".name", "duplicated node '%s'", node.Name)
+			}
+			nodes[node.Name] = struct{}{}
+			if _, _, err := node.GetReadAndWriteWeight(); err != nil {
+				v.addError(nodePath+".weight", "invalid weight '%s', %v", node.Weight, err)
+			}
+		}
+	}
+}
+
+func (v *validator) validateTable(path string, table *config.Table, clusters map[string]*config.DataSourceCluster) {
+	db, _, err := parseTable(table.Name)
+	if err != nil {
+		v.addError(path+".name", "%v", err)
+		return
+	}
+
+	cluster, ok := clusters[db]
+	if !ok {
+		v.addError(path+".name", "no such cluster '%s'", db)
+		return
+	}
+
+	var failed bool
+	for i, it := range table.DbRules {
+		if _, err = toSharder(it); err != nil {
+			v.addError(fmt.Sprintf("%s.db_rules[%d].expr", path, i), "%v", err)
+			failed = true
+		}
+	}
+	for i, it := range table.TblRules {
+		if _, err = toSharder(it); err != nil {
+			v.addError(fmt.Sprintf("%s.tbl_rules[%d].expr", path, i), "%v", err)
+			failed = true
+		}
+	}
+
+	if !v.validateTopology(path+".topology", table.Topology, cluster) {
+		failed = true
+	}
+	if !v.validateTopology(path+".shadow_topology", table.ShadowTopology, cluster) {
+		failed = true
+	}
+	if _, err = table.GetMaxExecutionTime(); err != nil {
+		v.addError(path+".max_execution_time", "%v", err)
+		failed = true
+	}
+
+	if failed {
+		return
+	}
+
+	// dry-run: build the virtual table just like booting.
+	if _, err = toVTable(table); err != nil {
+		v.addError(path, "%v", err)
+	}
+}
+
+func (v *validator) validateTopology(path string, topology *config.Topology, cluster *config.DataSourceCluster) bool {
+	if topology == nil {
+		return true
+	}
+
+	valid := true
+
+	if len(topology.TblPattern) > 0 {
+		if _, _, _, err := parseTopology(topology.TblPattern); err != nil {
+			v.addError(path+".tbl_pattern", "%v", err)
+			valid = false
+		}
+	}
+
+	if len(topology.DbPattern) < 1 {
+		return valid
+	}
+
+	format, begin, end, err := parseTopology(topology.DbPattern)
+	if err != nil {
+		v.addError(path+".db_pattern", "%v", err)
+		return false
+	}
+
+	groups := make(map[string]struct{})
+	for _, it := range cluster.Groups {
+		if it != nil {
+			groups[it.Name] = struct{}{}
+		}
+	}
+
+	var (
+		render  = getRender(format)
+		missing []string
+	)
+	if begin < 0 || end < 0 {
+		begin, end = 0, 0
+	}
+	for i := begin; i <= end; i++ {
+		if group := render(i); !hasKey(groups, group) {
+			missing = append(missing, group)
+		}
+	}
+	if len(missing) > 0 {
+		v.addError(path+".db_pattern", "no such groups in cluster '%s': %s", cluster.Name, strings.Join(missing, ","))
+		valid = false
+	}
+
+	return valid
+}
+
+// validateRequired checks the fields with tag `validate:"required"` recursively.
+func (v *validator) validateRequired(path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			v.validateRequired(path, value.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			v.validateRequired(fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+		}
+	case reflect.Struct:
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+
+			fieldPath := path
+			if !field.Anonymous {
+				fieldPath = joinPath(path, yamlName(field))
+			}
+
+			if field.Tag.Get("validate") == "required" && isEmptyValue(value.Field(i)) {
+				v.addError(fieldPath, "is required")
+				continue
+			}
+
+			v.validateRequired(fieldPath, value.Field(i))
+		}
+	}
+}
+
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if len(name) < 1 {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(parent, child string) string {
+	if len(parent) < 1 {
+		return child
+	}
+	return parent + "." + child
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+func hasKey(m map[string]struct{}, key string) bool {
+	_, ok := m[key]
+	return ok
+}
@@ -0,0 +1,92 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestValidate(t *testing.T) {
+	cfg, err := config.LoadV2(testdata.Path("fake_config.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, Validate(cfg))
+
+	cluster := cfg.Data.DataSourceClusters[0]
+	cluster.Tenant = "fake_tenant"
+	cluster.Groups[0].Nodes[0].Weight = "w10"
+	cluster.Groups[0].Nodes[0].Host = ""
+
+	table := cfg.Data.ShardingRule.Tables[0]
+	table.DbRules[0].Expr = "fakeShard(3)"
+	table.Topology.DbPattern = "employee_${0000...0001}"
+	table.ShadowTopology.TblPattern = "__test_student_${0000...07}"
+
+	cfg.Data.Tenants[0].SlowLogThreshold = "1 second"
+	cfg.Data.Tenants[0].MaxExecutionTime = "-1s"
+	table.MaxExecutionTime = "1 minute"
+	cfg.Data.Tenants[0].Quota = &config.Quota{QPS: -1}
+	cfg.Data.Listeners[0].TLS = &config.TLS{CertFile: "server.crt", RequireClientCert: true}
+	cfg.Data.Listeners[0].DefaultAuthPlugin = "dialog"
+	cfg.Data.Tenants[0].Users[0].Quota = &config.Quota{QueueTimeout: "1 second"}
+	cfg.Data.Tenants[0].Authenticator = &config.Authenticator{Type: "fake"}
+
+	err = Validate(cfg)
+	assert.Error(t, err)
+
+	var paths []string
+	for _, it := range err.(ValidationErrors) {
+		paths = append(paths, it.Path)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"data.clusters[0].groups[0].nodes[0].host",
+		"data.clusters[0].tenant",
+		"data.clusters[0].groups[0].nodes[0].weight",
+		"data.sharding_rule.tables[0].db_rules[0].expr",
+		"data.sharding_rule.tables[0].topology.db_pattern",
+		"data.sharding_rule.tables[0].shadow_topology.tbl_pattern",
+		"data.tenants[0].slow_log_threshold",
+		"data.tenants[0].max_execution_time",
+		"data.sharding_rule.tables[0].max_execution_time",
+		"data.tenants[0].quota",
+		"data.listeners[0].tls",
+		"data.listeners[0].tls.ca_file",
+		"data.listeners[0].default_auth_plugin",
+		"data.tenants[0].users[0].quota.queue_timeout",
+		"data.tenants[0].authenticator",
+	}, paths)
+}
+
+func TestValidateDryRun(t *testing.T) {
+	cfg, err := config.LoadV2(testdata.Path("fake_config.yaml"))
+	assert.NoError(t, err)
+
+	cfg.Data.ShardingRule.Tables[0].DbRules[0].Column = "fake_column"
+	err = Validate(cfg)
+	assert.Error(t, err)
+	assert.Equal(t, "data.sharding_rule.tables[0]", err.(ValidationErrors)[0].Path)
+}
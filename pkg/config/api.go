@@ -39,6 +39,7 @@ const (
 	DefaultConfigDataSourceClustersPath PathKey = "/arana-db/config/data/dataSourceClusters"
 	DefaultConfigDataShardingRulePath   PathKey = "/arana-db/config/data/shardingRule"
 	DefaultConfigDataTenantsPath        PathKey = "/arana-db/config/data/tenants"
+	DefaultConfigHistoryPath            PathKey = "/arana-db/config/history"
 )
 
 const (
@@ -57,6 +58,9 @@ var (
 	storeOperate StoreOperate
 )
 
+// ErrKeyNotFound is returned by StoreOperate.Get if the key doesn't exist, some stores return an empty value instead.
+var ErrKeyNotFound = errors.New("config key not found")
+
 func GetStoreOperate() (StoreOperate, error) {
 	if storeOperate != nil {
 		return storeOperate, nil
@@ -99,6 +103,9 @@ type StoreOperate interface {
 	//Get get a configuration
 	Get(key PathKey) ([]byte, error)
 
+	//Delete delete a configuration
+	Delete(key PathKey) error
+
 	//Watch Monitor changes of the key
 	Watch(key PathKey) (<-chan []byte, error)
 
@@ -81,13 +81,18 @@ type Observer func()
 type ConfigOptions struct {
 	StoreName string                 `yaml:"name"`
 	Options   map[string]interface{} `yaml:"options"`
+	SecretKey string                 `yaml:"secret_key"` // the key to decrypt secrets, eg: ${env:ARANA_SECRET_KEY}
 }
 
 type Center struct {
 	initialize   int32
 	storeOperate StoreOperate
 	confHolder   atomic.Value // 里面持有了最新的 *Configuration 对象
+	rawHolder    atomic.Value // holds the latest *Configuration whose secret references are not resolved
+	resolver     *SecretResolver
 	lock         sync.RWMutex
+	historyLock  sync.Mutex
+	updateLock   sync.Mutex
 	observers    []Observer
 	watchCancels []context.CancelFunc
 }
@@ -102,10 +107,17 @@ func NewCenter(options ConfigOptions) (*Center, error) {
 		return nil, err
 	}
 
+	// the secret key itself could be a reference of env or file
+	secretKey, err := NewSecretResolver("").Resolve(options.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Center{
 		confHolder:   atomic.Value{},
 		lock:         sync.RWMutex{},
 		storeOperate: operate,
+		resolver:     NewSecretResolver(secretKey),
 		observers:    make([]Observer, 0, 2),
 	}, nil
 }
@@ -133,7 +145,9 @@ func (c *Center) LoadContext(ctx context.Context) (*Configuration, error) {
 		if err != nil {
 			return nil, err
 		}
-		c.confHolder.Store(cfg)
+		if err = c.setConfiguration(cfg); err != nil {
+			return nil, err
+		}
 
 		out, _ := yaml.Marshal(cfg)
 		log.Infof("load configuration : \n%s", string(out))
@@ -144,15 +158,101 @@ func (c *Center) LoadContext(ctx context.Context) (*Configuration, error) {
 	return val.(*Configuration), nil
 }
 
+// LoadRaw loads the configuration whose secret references are not resolved.
+func (c *Center) LoadRaw() (*Configuration, error) {
+	if _, err := c.Load(); err != nil {
+		return nil, err
+	}
+	return c.rawHolder.Load().(*Configuration), nil
+}
+
 func (c *Center) ImportConfiguration(cfg *Configuration) error {
+	return c.ImportConfigurationContext(context.Background(), cfg)
+}
+
+// ImportConfigurationContext persists the configuration, and notifies the observers.
+func (c *Center) ImportConfigurationContext(ctx context.Context, cfg *Configuration) error {
+	if err := c.setConfiguration(cfg); err != nil {
+		return err
+	}
+	if err := c.PersistContext(ctx); err != nil {
+		return err
+	}
+	c.notifyObservers()
+	return nil
+}
+
+// Update applies the modification on a copy of current configuration, then imports it.
+func (c *Center) Update(ctx context.Context, modify func(cfg *Configuration) error) error {
+	c.updateLock.Lock()
+	defer c.updateLock.Unlock()
+
+	current, err := c.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := current.Clone()
+	if err != nil {
+		return err
+	}
+	if err = modify(cfg); err != nil {
+		return err
+	}
+
+	return c.ImportConfigurationContext(ctx, cfg)
+}
+
+// setConfiguration holds the raw configuration, and the copy whose secret references are resolved.
+func (c *Center) setConfiguration(raw *Configuration) error {
+	resolver := c.resolver
+	if resolver == nil {
+		resolver = NewSecretResolver("")
+	}
+
+	cfg, err := raw.Clone()
+	if err != nil {
+		return err
+	}
+	if err = resolver.resolveConfiguration(cfg); err != nil {
+		return err
+	}
+
+	c.rawHolder.Store(raw)
 	c.confHolder.Store(cfg)
-	return c.Persist()
+	return nil
+}
+
+// Subscribe registers an observer which will be notified after the configuration changed,
+// watching of the config store starts along with the first subscription.
+func (c *Center) Subscribe(observer Observer) error {
+	c.lock.Lock()
+	c.observers = append(c.observers, observer)
+	c.lock.Unlock()
+
+	return c.watchFromStore()
 }
 
 func (c *Center) loadFromStore(ctx context.Context) (*Configuration, error) {
 	operate := c.storeOperate
 
-	cfg := &Configuration{
+	cfg := newConfiguration()
+
+	for k := range ConfigKeyMapping {
+		val, err := operate.Get(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := unmarshalKey(cfg, k, val); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func newConfiguration() *Configuration {
+	return &Configuration{
 		TypeMeta: TypeMeta{},
 		Metadata: make(map[string]interface{}),
 		Data: &Data{
@@ -163,26 +263,21 @@ func (c *Center) loadFromStore(ctx context.Context) (*Configuration, error) {
 			ShardingRule:       &ShardingRule{},
 		},
 	}
+}
 
-	for k := range ConfigKeyMapping {
-		val, err := operate.Get(k)
-		if err != nil {
-			return nil, err
-		}
-
-		supplier, ok := _configValSupplier[k]
+func unmarshalKey(cfg *Configuration, key PathKey, val []byte) error {
+	supplier, ok := _configValSupplier[key]
 
-		if !ok {
-			return nil, fmt.Errorf("%s not register val supplier", k)
-		}
+	if !ok {
+		return fmt.Errorf("%s not register val supplier", key)
+	}
 
-		if len(val) != 0 {
-			if err := json.Unmarshal(val, supplier(cfg)); err != nil {
-				return nil, err
-			}
+	if len(val) != 0 {
+		if err := json.Unmarshal(val, supplier(cfg)); err != nil {
+			return err
 		}
 	}
-	return cfg, nil
+	return nil
 }
 
 func (c *Center) watchFromStore() error {
@@ -207,43 +302,76 @@ func (c *Center) watchFromStore() error {
 }
 
 func (c *Center) watchKey(ctx context.Context, key PathKey, ch <-chan []byte) {
-	consumer := func(ret []byte) {
+	consumer := func(ret []byte) bool {
 		c.lock.Lock()
 		defer c.lock.Unlock()
 
 		supplier, ok := _configValSupplier[key]
 		if !ok {
 			log.Errorf("%s not register val supplier", key)
-			return
+			return false
 		}
 
-		cfg := c.confHolder.Load().(*Configuration)
+		current, ok := c.rawHolder.Load().(*Configuration)
+		if !ok {
+			return false
+		}
+
+		// copy on write, the configuration loaded before should never be changed
+		cfg, err := current.Clone()
+		if err != nil {
+			log.Errorf("failed to clone configuration: %v", err)
+			return false
+		}
 
 		if len(ret) != 0 {
 			if err := json.Unmarshal(ret, supplier(cfg)); err != nil {
-				log.Errorf("", err)
+				log.Errorf("failed to unmarshal %s: %v", key, err)
+				return false
 			}
 		}
 
-		c.confHolder.Store(cfg)
+		if err := c.setConfiguration(cfg); err != nil {
+			log.Errorf("failed to apply %s: %v", key, err)
+			return false
+		}
+		return true
 	}
 
 	for {
 		select {
-		case ret := <-ch:
-			consumer(ret)
+		case ret, ok := <-ch:
+			if !ok {
+				log.Infof("stop watch : %s", key)
+				return
+			}
+			if consumer(ret) {
+				c.notifyObservers()
+			}
 		case <-ctx.Done():
 			log.Infof("stop watch : %s", key)
+			return
 		}
 	}
 }
 
+func (c *Center) notifyObservers() {
+	c.lock.RLock()
+	observers := make([]Observer, len(c.observers))
+	copy(observers, c.observers)
+	c.lock.RUnlock()
+
+	for _, observer := range observers {
+		observer()
+	}
+}
+
 func (c *Center) Persist() error {
 	return c.PersistContext(context.Background())
 }
 
 func (c *Center) PersistContext(ctx context.Context) error {
-	val := c.confHolder.Load()
+	val := c.rawHolder.Load()
 	if val == nil {
 		return errors.New("ConfHolder.load is nil")
 	}
@@ -255,11 +383,14 @@ func (c *Center) PersistContext(ctx context.Context) error {
 		return fmt.Errorf("config json.marshal failed  %v err:", err)
 	}
 
+	snapshot := make(map[PathKey]string, len(ConfigKeyMapping))
 	for k, v := range ConfigKeyMapping {
+		snapshot[k] = gjson.GetBytes(configJson, v).String()
 
-		if err := c.storeOperate.Save(k, []byte(gjson.GetBytes(configJson, v).String())); err != nil {
+		if err := c.storeOperate.Save(k, []byte(snapshot[k])); err != nil {
 			return err
 		}
 	}
-	return nil
+
+	return c.recordVersion(ctx, snapshot)
 }
@@ -0,0 +1,104 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+import (
+	"github.com/ghodss/yaml"
+
+	"github.com/pkg/errors"
+
+	"github.com/tidwall/gjson"
+)
+
+// Difference represents the difference of a config path between the local and the stored.
+type Difference struct {
+	Key    PathKey
+	Local  string // normalized yaml of local value
+	Stored string // normalized yaml of stored value
+}
+
+// Diff compares the given configuration with the stored one key by key, returns the changed keys only.
+func (c *Center) Diff(cfg *Configuration) ([]*Difference, error) {
+	configJson, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal configuration")
+	}
+
+	keys := make([]PathKey, 0, len(ConfigKeyMapping))
+	for k := range ConfigKeyMapping {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	var diffs []*Difference
+	for _, k := range keys {
+		stored, err := c.storeOperate.Get(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s", k)
+		}
+
+		next := &Difference{Key: k}
+		if next.Stored, err = normalize(k, stored); err != nil {
+			return nil, errors.Wrapf(err, "invalid stored value of %s", k)
+		}
+		if next.Local, err = normalize(k, []byte(gjson.GetBytes(configJson, ConfigKeyMapping[k]).String())); err != nil {
+			return nil, errors.Wrapf(err, "invalid local value of %s", k)
+		}
+
+		if next.Local != next.Stored {
+			diffs = append(diffs, next)
+		}
+	}
+
+	return diffs, nil
+}
+
+// normalize decodes the value of key into its model, then encodes it as yaml,
+// so that the values can be compared regardless of the format and the order of fields.
+func normalize(key PathKey, val []byte) (string, error) {
+	supplier, ok := _configValSupplier[key]
+	if !ok {
+		return "", errors.Errorf("%s not register val supplier", key)
+	}
+
+	cfg := &Configuration{Data: &Data{}}
+	if len(val) != 0 {
+		if err := json.Unmarshal(val, supplier(cfg)); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+
+	out, err := yaml.Marshal(supplier(cfg))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	switch s := string(out); s {
+	case "null\n", "[]\n", "{}\n":
+		return "", nil
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore map[PathKey][]byte
+
+func (f fakeStore) Close() error                         { return nil }
+func (f fakeStore) Init(map[string]interface{}) error    { return nil }
+func (f fakeStore) Name() string                         { return "fake" }
+func (f fakeStore) Watch(PathKey) (<-chan []byte, error) { return nil, nil }
+func (f fakeStore) Get(key PathKey) ([]byte, error)      { return f[key], nil }
+func (f fakeStore) Save(key PathKey, val []byte) error   { f[key] = val; return nil }
+func (f fakeStore) Delete(key PathKey) error             { delete(f, key); return nil }
+
+func TestDiff(t *testing.T) {
+	stored, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+
+	c := &Center{storeOperate: make(fakeStore)}
+	assert.NoError(t, c.ImportConfiguration(stored))
+
+	local, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+
+	diffs, err := c.Diff(local)
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	local.Data.Tenants[0].Users[0].Password = "654321"
+	local.Data.ShardingRule = nil
+
+	diffs, err = c.Diff(local)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, DefaultConfigDataShardingRulePath, diffs[0].Key)
+	assert.Empty(t, diffs[0].Local)
+	assert.Equal(t, DefaultConfigDataTenantsPath, diffs[1].Key)
+	assert.Contains(t, diffs[1].Local, "654321")
+	assert.Contains(t, diffs[1].Stored, "123456")
+}
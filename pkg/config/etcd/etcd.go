@@ -28,6 +28,8 @@ import (
 import (
 	etcdv3 "github.com/dubbogo/gost/database/kv/etcd/v3"
 
+	"github.com/pkg/errors"
+
 	"go.etcd.io/etcd/api/v3/mvccpb"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -75,6 +77,9 @@ func (c *storeOperate) Save(key config.PathKey, val []byte) error {
 
 func (c *storeOperate) Get(key config.PathKey) ([]byte, error) {
 	v, err := c.client.Get(string(key))
+	if errors.Cause(err) == etcdv3.ErrKVPairNotFound {
+		return nil, config.ErrKeyNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +87,10 @@ func (c *storeOperate) Get(key config.PathKey) ([]byte, error) {
 	return []byte(v), nil
 }
 
+func (c *storeOperate) Delete(key config.PathKey) error {
+	return c.client.Delete(string(key))
+}
+
 type etcdWatcher struct {
 	revision  int64
 	lock      *sync.RWMutex
@@ -18,9 +18,15 @@
 package file
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"sync"
+	"time"
 )
 
 import (
@@ -36,6 +42,14 @@ import (
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
+const (
+	_contentKey  = "content"
+	_pathKey     = "path"
+	_intervalKey = "interval"
+
+	_defaultInterval = 5 * time.Second
+)
+
 func init() {
 	config.Register(&storeOperate{})
 }
@@ -44,44 +58,186 @@ type storeOperate struct {
 	lock      *sync.RWMutex
 	receivers map[config.PathKey][]chan []byte
 	cfgJson   map[config.PathKey]string
+
+	path     string   // the path of config file, the content will be reloaded when the file changed
+	checksum [16]byte // md5 checksum of last loaded file content
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (s *storeOperate) Init(options map[string]interface{}) error {
 	s.lock = &sync.RWMutex{}
 	s.receivers = make(map[config.PathKey][]chan []byte)
-	var cfg config.Configuration
-	if err := yaml.Unmarshal([]byte(options["content"].(string)), &cfg); err != nil {
-		return errors.Wrapf(err, "failed to unmarshal config")
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	if path, ok := options[_pathKey].(string); ok && len(path) > 0 {
+		return s.initWithPath(path, options)
 	}
-	configJson, err := json.Marshal(cfg)
+
+	content, _ := options[_contentKey].(string)
+	cfgJson, err := parseContent([]byte(content))
 	if err != nil {
-		return fmt.Errorf("config json.marshal failed  %v err:", err)
+		return err
 	}
-	s.initCfgJsonMap(string(configJson))
+	s.cfgJson = cfgJson
+
+	log.Debugf("[ConfigCenter][File] load config content : %#v", s.cfgJson)
 	return nil
 }
 
-func (s *storeOperate) initCfgJsonMap(val string) {
-	s.cfgJson = make(map[config.PathKey]string)
+func (s *storeOperate) initWithPath(path string, options map[string]interface{}) error {
+	s.path, _ = filepath.Abs(path)
+	s.interval = _defaultInterval
+	if val, ok := options[_intervalKey].(string); ok && len(val) > 0 {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Wrapf(err, "invalid watch interval %s", val)
+		}
+		s.interval = interval
+	}
 
-	for k, v := range config.ConfigKeyMapping {
-		s.cfgJson[k] = gjson.Get(val, v).String()
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load config file %s", s.path)
+	}
+	if s.cfgJson, err = parseContent(content); err != nil {
+		return err
 	}
+	s.checksum = md5.Sum(content)
 
-	log.Debugf("[ConfigCenter][File] load config content : %#v", s.cfgJson)
+	log.Debugf("[ConfigCenter][File] load config file %s: %#v", s.path, s.cfgJson)
+
+	go s.watchFile(s.ctx)
+
+	return nil
+}
+
+// watchFile polls the config file, and pushes the changed keys to receivers.
+func (s *storeOperate) watchFile(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.reload(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *storeOperate) reload(ctx context.Context) {
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		log.Errorf("[ConfigCenter][File] failed to read config file %s: %v", s.path, err)
+		return
+	}
+
+	checksum := md5.Sum(content)
+	if bytes.Equal(checksum[:], s.checksum[:]) {
+		return
+	}
+
+	cfgJson, err := parseContent(content)
+	if err != nil {
+		log.Errorf("[ConfigCenter][File] failed to parse config file %s: %v", s.path, err)
+		return
+	}
+	s.checksum = checksum
+
+	s.lock.Lock()
+	changes := make(map[config.PathKey][]byte)
+	for k, v := range cfgJson {
+		if s.cfgJson[k] != v {
+			changes[k] = []byte(v)
+		}
+		s.cfgJson[k] = v
+	}
+	receivers := s.copyReceivers()
+	s.lock.Unlock()
+
+	log.Infof("[ConfigCenter][File] config file %s changed, keys: %d", s.path, len(changes))
+
+	for k, v := range changes {
+		if !push(ctx, receivers[k], v) {
+			return
+		}
+	}
+}
+
+func push(ctx context.Context, receivers []chan []byte, val []byte) bool {
+	for _, rec := range receivers {
+		select {
+		case rec <- val:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+func (s *storeOperate) copyReceivers() map[config.PathKey][]chan []byte {
+	receivers := make(map[config.PathKey][]chan []byte, len(s.receivers))
+	for k, v := range s.receivers {
+		receivers[k] = append([]chan []byte(nil), v...)
+	}
+	return receivers
+}
+
+func parseContent(content []byte) (map[config.PathKey]string, error) {
+	var cfg config.Configuration
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal config")
+	}
+	configJson, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config json.marshal failed  %v err:", err)
+	}
+
+	cfgJson := make(map[config.PathKey]string)
+	for k, v := range config.ConfigKeyMapping {
+		cfgJson[k] = gjson.GetBytes(configJson, v).String()
+	}
+	return cfgJson, nil
 }
 
+// Save saves the value in memory only, the config file will never be changed.
+// So the history versions are in memory too, they will be lost once restarted.
 func (s *storeOperate) Save(key config.PathKey, val []byte) error {
+	s.lock.Lock()
+	if s.cfgJson[key] == string(val) {
+		s.lock.Unlock()
+		return nil
+	}
+	s.cfgJson[key] = string(val)
+	receivers := append([]chan []byte(nil), s.receivers[key]...)
+	s.lock.Unlock()
+
+	push(s.ctx, receivers, val)
 	return nil
 }
 
 func (s *storeOperate) Get(key config.PathKey) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	val := []byte(s.cfgJson[key])
 	return val, nil
 }
 
-//Watch TODO change notification through file inotify mechanism
+func (s *storeOperate) Delete(key config.PathKey) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.cfgJson, key)
+	return nil
+}
+
+// Watch watches the changes of key, the changes will be pushed when the config file is modified or the key is saved.
 func (s *storeOperate) Watch(key config.PathKey) (<-chan []byte, error) {
+	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	if _, ok := s.receivers[key]; !ok {
@@ -100,5 +256,6 @@ func (s *storeOperate) Name() string {
 }
 
 func (s *storeOperate) Close() error {
+	s.cancel()
 	return nil
 }
@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/testdata"
+)
+
+func TestWatchFile(t *testing.T) {
+	content, err := ioutil.ReadFile(testdata.Path("fake_config.yaml"))
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "arana-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, content, 0644))
+
+	s := &storeOperate{}
+	err = s.Init(map[string]interface{}{
+		"path":     path,
+		"interval": "10ms",
+	})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	val, err := s.Get(config.DefaultConfigDataTenantsPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(val), `"123456"`)
+
+	tenantsCh, err := s.Watch(config.DefaultConfigDataTenantsPath)
+	assert.NoError(t, err)
+	listenersCh, err := s.Watch(config.DefaultConfigDataListenersPath)
+	assert.NoError(t, err)
+
+	changed := bytes.Replace(content, []byte(`password: "123456"`), []byte(`password: "654321"`), 1)
+	assert.NoError(t, ioutil.WriteFile(path, changed, 0644))
+
+	select {
+	case ret := <-tenantsCh:
+		var tenants []*config.Tenant
+		assert.NoError(t, json.Unmarshal(ret, &tenants))
+		assert.Equal(t, "654321", tenants[0].Users[0].Password)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "should receive changes of tenants")
+	}
+
+	select {
+	case <-listenersCh:
+		assert.Fail(t, "should not receive changes of listeners")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	val, err = s.Get(config.DefaultConfigDataTenantsPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(val), `"654321"`)
+}
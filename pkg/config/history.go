@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+// _maxHistory is the max amount of versions to keep, older versions will be purged.
+const _maxHistory = 32
+
+const _historyVersionsPath = DefaultConfigHistoryPath + "/versions"
+
+// Version represents a persisted version of configuration.
+type Version struct {
+	Version   int64     `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    string    `json:"author"`
+	Checksum  string    `json:"checksum"`
+}
+
+type authorKey struct{}
+
+// WithAuthor returns a context which carries the author who persists the configuration.
+func WithAuthor(ctx context.Context, author string) context.Context {
+	return context.WithValue(ctx, authorKey{}, author)
+}
+
+func authorOf(ctx context.Context) string {
+	if author, ok := ctx.Value(authorKey{}).(string); ok && len(author) > 0 {
+		return author
+	}
+	return "unknown"
+}
+
+func historyVersionPath(version int64) PathKey {
+	return PathKey(fmt.Sprintf("%s/%d", DefaultConfigHistoryPath, version))
+}
+
+// ListVersions returns the recorded versions, from the oldest to the newest.
+func (c *Center) ListVersions() ([]*Version, error) {
+	val, err := c.storeOperate.Get(_historyVersionsPath)
+	if errors.Is(err, ErrKeyNotFound) {
+		// nothing is recorded yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config versions")
+	}
+
+	var versions []*Version
+	if len(val) != 0 {
+		if err = json.Unmarshal(val, &versions); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal config versions")
+		}
+	}
+	return versions, nil
+}
+
+// LoadVersion loads the configuration of given version.
+func (c *Center) LoadVersion(version int64) (*Configuration, error) {
+	val, err := c.storeOperate.Get(historyVersionPath(version))
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, errors.Wrapf(err, "failed to get config version %d", version)
+	}
+	if len(val) == 0 {
+		return nil, errors.Errorf("no such config version %d", version)
+	}
+
+	var snapshot map[PathKey]string
+	if err = json.Unmarshal(val, &snapshot); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal config version %d", version)
+	}
+
+	cfg := newConfiguration()
+	for k := range ConfigKeyMapping {
+		if err = unmarshalKey(cfg, k, []byte(snapshot[k])); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal config version %d", version)
+		}
+	}
+	return cfg, nil
+}
+
+// Rollback persists the configuration of given version as the newest version,
+// the changes will be propagated to all watchers of the config store.
+func (c *Center) Rollback(ctx context.Context, version int64) error {
+	cfg, err := c.LoadVersion(version)
+	if err != nil {
+		return err
+	}
+
+	if err = c.ImportConfigurationContext(ctx, cfg); err != nil {
+		return errors.Wrapf(err, "failed to rollback to config version %d", version)
+	}
+
+	log.Infof("rollback configuration to version %d by %s", version, authorOf(ctx))
+	return nil
+}
+
+// recordVersion records the snapshot as a new version, nothing will be recorded if it equals to the newest version.
+func (c *Center) recordVersion(ctx context.Context, snapshot map[PathKey]string) error {
+	c.historyLock.Lock()
+	defer c.historyLock.Unlock()
+
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	checksum := sha256.Sum256(content)
+
+	versions, err := c.ListVersions()
+	if err != nil {
+		return err
+	}
+
+	next := &Version{
+		Version:   1,
+		Timestamp: time.Now(),
+		Author:    authorOf(ctx),
+		Checksum:  hex.EncodeToString(checksum[:]),
+	}
+	if n := len(versions); n > 0 {
+		if versions[n-1].Checksum == next.Checksum {
+			return nil
+		}
+		next.Version = versions[n-1].Version + 1
+	}
+
+	if err = c.storeOperate.Save(historyVersionPath(next.Version), content); err != nil {
+		return errors.Wrapf(err, "failed to save config version %d", next.Version)
+	}
+
+	versions = append(versions, next)
+	for len(versions) > _maxHistory {
+		if err = c.storeOperate.Delete(historyVersionPath(versions[0].Version)); err != nil {
+			log.Warnf("failed to purge config version %d: %v", versions[0].Version, err)
+		}
+		versions = versions[1:]
+	}
+
+	val, err := json.Marshal(versions)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = c.storeOperate.Save(_historyVersionsPath, val); err != nil {
+		return errors.Wrap(err, "failed to save config versions")
+	}
+
+	log.Infof("record configuration version %d by %s", next.Version, next.Author)
+	return nil
+}
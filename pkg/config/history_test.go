@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"testing"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	cfg, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+
+	c := &Center{storeOperate: make(fakeStore)}
+	assert.NoError(t, c.ImportConfigurationContext(WithAuthor(context.Background(), "foo"), cfg))
+
+	next, err := cfg.Clone()
+	assert.NoError(t, err)
+	next.Data.ShardingRule.Tables[0].AllowFullScan = false
+	assert.NoError(t, c.ImportConfiguration(next))
+	// nothing changed, no version should be recorded
+	assert.NoError(t, c.ImportConfiguration(next))
+
+	versions, err := c.ListVersions()
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, int64(1), versions[0].Version)
+	assert.Equal(t, "foo", versions[0].Author)
+	assert.Equal(t, int64(2), versions[1].Version)
+	assert.Equal(t, "unknown", versions[1].Author)
+
+	assert.NoError(t, c.Rollback(WithAuthor(context.Background(), "bar"), 1))
+
+	versions, err = c.ListVersions()
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, "bar", versions[2].Author)
+	assert.Equal(t, versions[0].Checksum, versions[2].Checksum)
+
+	current, err := c.Load()
+	assert.NoError(t, err)
+	assert.True(t, current.Data.ShardingRule.Tables[0].AllowFullScan)
+
+	_, err = c.LoadVersion(4)
+	assert.Error(t, err)
+}
+
+func TestHistoryPurge(t *testing.T) {
+	cfg, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+
+	store := make(fakeStore)
+	c := &Center{storeOperate: store}
+	for i := 0; i < _maxHistory+2; i++ {
+		cfg.Metadata["revision"] = i
+		assert.NoError(t, c.ImportConfiguration(cfg))
+	}
+
+	versions, err := c.ListVersions()
+	assert.NoError(t, err)
+	assert.Len(t, versions, _maxHistory)
+	assert.Equal(t, int64(3), versions[0].Version)
+
+	_, err = c.LoadVersion(2)
+	assert.Error(t, err)
+	_, err = c.LoadVersion(3)
+	assert.NoError(t, err)
+
+	// the purged versions are deleted from store
+	for _, version := range []int64{1, 2} {
+		_, ok := store[historyVersionPath(version)]
+		assert.False(t, ok)
+	}
+}
+
+// strictStore returns ErrKeyNotFound if the key doesn't exist, like etcd.
+type strictStore struct {
+	fakeStore
+}
+
+func (s strictStore) Get(key PathKey) ([]byte, error) {
+	if val, ok := s.fakeStore[key]; ok {
+		return val, nil
+	}
+	return nil, errors.Wrapf(ErrKeyNotFound, "get key %s", key)
+}
+
+func TestHistoryKeyNotFound(t *testing.T) {
+	cfg, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+
+	c := &Center{storeOperate: strictStore{make(fakeStore)}}
+
+	versions, err := c.ListVersions()
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	assert.NoError(t, c.ImportConfiguration(cfg))
+	versions, err = c.ListVersions()
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	_, err = c.LoadVersion(2)
+	assert.Error(t, err)
+	_, err = c.LoadVersion(1)
+	assert.NoError(t, err)
+}
@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"time"
 )
 
 import (
@@ -77,15 +78,47 @@ type (
 	Tenant struct {
 		Name  string  `validate:"required" yaml:"name" json:"name"`
 		Users []*User `validate:"required" yaml:"users" json:"users"`
+		// SlowLogThreshold is the threshold of slow query, eg: 500ms, the slow log is disabled if empty.
+		SlowLogThreshold string `yaml:"slow_log_threshold" json:"slow_log_threshold,omitempty"`
+		// MaxExecutionTime is the default timeout of statements, eg: 30s, unlimited if empty.
+		MaxExecutionTime string `yaml:"max_execution_time" json:"max_execution_time,omitempty"`
+		// Quota limits the total resource usage of all users of tenant.
+		Quota *Quota `yaml:"quota" json:"quota,omitempty"`
+		// RequireTLS requires all users of tenant to connect with TLS.
+		RequireTLS bool `yaml:"require_tls" json:"require_tls,omitempty"`
+		// Authenticator authenticates the users of tenant, the users in config are used if nil.
+		Authenticator *Authenticator `yaml:"authenticator" json:"authenticator,omitempty"`
+	}
+
+	// Authenticator represents the authenticator of tenant.
+	Authenticator struct {
+		// Type is the type of authenticator, eg: static, file, http.
+		Type string `yaml:"type" json:"type"`
+		// Options is the options of authenticator, eg: path of file authenticator.
+		Options map[string]string `yaml:"options" json:"options,omitempty"`
+	}
+
+	// Quota represents the resource limits of tenant or user, zero means unlimited.
+	Quota struct {
+		// QPS is the max queries per second.
+		QPS int `yaml:"qps" json:"qps,omitempty"`
+		// MaxConcurrency is the max concurrent queries.
+		MaxConcurrency int `yaml:"max_concurrency" json:"max_concurrency,omitempty"`
+		// MaxConnections is the max frontend connections.
+		MaxConnections int `yaml:"max_connections" json:"max_connections,omitempty"`
+		// QueueSize is the max queries waiting for the limits, the queries over limits will be rejected immediately if zero.
+		QueueSize int `yaml:"queue_size" json:"queue_size,omitempty"`
+		// QueueTimeout is the max waiting time of queued queries, eg: 500ms, default is 1s.
+		QueueTimeout string `yaml:"queue_timeout" json:"queue_timeout,omitempty"`
 	}
 
 	DataSourceCluster struct {
-		Name        string         `yaml:"name" json:"name"`
+		Name        string         `validate:"required" yaml:"name" json:"name"`
 		Type        DataSourceType `yaml:"type" json:"type"`
 		SqlMaxLimit int            `default:"-1" yaml:"sql_max_limit" json:"sql_max_limit,omitempty"`
-		Tenant      string         `yaml:"tenant" json:"tenant"`
+		Tenant      string         `validate:"required" yaml:"tenant" json:"tenant"`
 		ConnProps   *ConnProp      `yaml:"conn_props" json:"conn_props,omitempty"`
-		Groups      []*Group       `yaml:"groups" json:"groups"`
+		Groups      []*Group       `validate:"required" yaml:"groups" json:"groups"`
 	}
 
 	ConnProp struct {
@@ -95,14 +128,14 @@ type (
 	}
 
 	Group struct {
-		Name  string  `yaml:"name" json:"name"`
-		Nodes []*Node `yaml:"nodes" json:"nodes"`
+		Name  string  `validate:"required" yaml:"name" json:"name"`
+		Nodes []*Node `validate:"required" yaml:"nodes" json:"nodes"`
 	}
 
 	Node struct {
-		Name      string            `yaml:"name" json:"name"`
-		Host      string            `yaml:"host" json:"host"`
-		Port      int               `yaml:"port" json:"port"`
+		Name      string            `validate:"required" yaml:"name" json:"name"`
+		Host      string            `validate:"required" yaml:"host" json:"host"`
+		Port      int               `validate:"required" yaml:"port" json:"port"`
 		Username  string            `yaml:"username" json:"username"`
 		Password  string            `yaml:"password" json:"password"`
 		Database  string            `yaml:"database" json:"database"`
@@ -116,29 +149,59 @@ type (
 	}
 
 	Listener struct {
-		ProtocolType  string         `yaml:"protocol_type" json:"protocol_type"`
-		SocketAddress *SocketAddress `yaml:"socket_address" json:"socket_address"`
+		ProtocolType  string         `validate:"required" yaml:"protocol_type" json:"protocol_type"`
+		SocketAddress *SocketAddress `validate:"required" yaml:"socket_address" json:"socket_address"`
 		ServerVersion string         `yaml:"server_version" json:"server_version"`
+		// TLS enables TLS for client connections if not nil.
+		TLS *TLS `yaml:"tls" json:"tls,omitempty"`
+		// DefaultAuthPlugin is the auth plugin advertised in handshake, default is mysql_native_password.
+		DefaultAuthPlugin string `yaml:"default_auth_plugin" json:"default_auth_plugin,omitempty"`
+		// RSAPrivateKey is the PEM encoded RSA private key file to exchange passwords without TLS,
+		// a temporary key will be generated if empty.
+		RSAPrivateKey string `yaml:"rsa_private_key" json:"rsa_private_key,omitempty"`
+		// AdminToken is the bearer token required by the http admin api, which could be a secret reference.
+		// The api which changes configuration is refused if empty.
+		AdminToken string `yaml:"admin_token" json:"admin_token,omitempty"`
+	}
+
+	// TLS represents the TLS config of listener.
+	TLS struct {
+		// CertFile is the PEM encoded certificate of server.
+		CertFile string `yaml:"cert_file" json:"cert_file"`
+		// KeyFile is the PEM encoded private key of server.
+		KeyFile string `yaml:"key_file" json:"key_file"`
+		// CAFile is the PEM encoded CA certificates to verify the client certificates.
+		CAFile string `yaml:"ca_file" json:"ca_file,omitempty"`
+		// RequireClientCert requires every client to present a certificate signed by CA, aka mutual TLS.
+		RequireClientCert bool `yaml:"require_client_cert" json:"require_client_cert,omitempty"`
 	}
 
 	User struct {
-		Username string `yaml:"username" json:"username"`
+		Username string `validate:"required" yaml:"username" json:"username"`
 		Password string `yaml:"password" json:"password"`
+		// Quota limits the resource usage of user.
+		Quota *Quota `yaml:"quota" json:"quota,omitempty"`
+		// RequireTLS requires the user to connect with TLS.
+		RequireTLS bool `yaml:"require_tls" json:"require_tls,omitempty"`
+		// CertSubject requires the user to present a verified client certificate with the subject, eg: CN=arana,O=arana-db.
+		CertSubject string `yaml:"cert_subject" json:"cert_subject,omitempty"`
 	}
 
 	Table struct {
-		Name           string            `yaml:"name" json:"name"`
+		Name           string            `validate:"required" yaml:"name" json:"name"`
 		AllowFullScan  bool              `yaml:"allow_full_scan" json:"allow_full_scan,omitempty"`
 		DbRules        []*Rule           `yaml:"db_rules" json:"db_rules"`
 		TblRules       []*Rule           `yaml:"tbl_rules" json:"tbl_rules"`
 		Topology       *Topology         `yaml:"topology" json:"topology"`
 		ShadowTopology *Topology         `yaml:"shadow_topology" json:"shadow_topology"`
 		Attributes     map[string]string `yaml:"attributes" json:"attributes"`
+		// MaxExecutionTime is the timeout of statements on the table, eg: 10s, it overrides the timeout of tenant.
+		MaxExecutionTime string `yaml:"max_execution_time" json:"max_execution_time,omitempty"`
 	}
 
 	Rule struct {
-		Column string `yaml:"column" json:"column"`
-		Expr   string `yaml:"expr" json:"expr"`
+		Column string `validate:"required" yaml:"column" json:"column"`
+		Expr   string `validate:"required" yaml:"expr" json:"expr"`
 	}
 
 	Topology struct {
@@ -175,6 +238,19 @@ func LoadV2(path string) (*Configuration, error) {
 	return cfg, nil
 }
 
+// Clone returns a deep copy of the configuration.
+func (c *Configuration) Clone() (*Configuration, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var cloned Configuration
+	if err = json.Unmarshal(b, &cloned); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &cloned, nil
+}
+
 var reg = regexp.MustCompile(`^[rR]([0-9]+)[wW]([0-9]+)$`)
 
 func (d *Node) GetReadAndWriteWeight() (int, int, error) {
@@ -194,6 +270,54 @@ func (d *Node) GetReadAndWriteWeight() (int, int, error) {
 	return readWeight, writeWeight, nil
 }
 
+// GetSlowLogThreshold returns the threshold of slow query, returns zero if the slow log is disabled.
+func (t *Tenant) GetSlowLogThreshold() (time.Duration, error) {
+	if len(t.SlowLogThreshold) < 1 {
+		return 0, nil
+	}
+	threshold, err := time.ParseDuration(t.SlowLogThreshold)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid slow log threshold '%s'", t.SlowLogThreshold)
+	}
+	return threshold, nil
+}
+
+// GetMaxExecutionTime returns the default timeout of statements, returns zero if unlimited.
+func (t *Tenant) GetMaxExecutionTime() (time.Duration, error) {
+	return parseMaxExecutionTime(t.MaxExecutionTime)
+}
+
+// GetMaxExecutionTime returns the timeout of statements on the table, returns zero if not set.
+func (t *Table) GetMaxExecutionTime() (time.Duration, error) {
+	return parseMaxExecutionTime(t.MaxExecutionTime)
+}
+
+func parseMaxExecutionTime(s string) (time.Duration, error) {
+	if len(s) < 1 {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid max execution time '%s'", s)
+	}
+	if timeout < 0 {
+		return 0, errors.Errorf("invalid max execution time '%s'", s)
+	}
+	return timeout, nil
+}
+
+// GetQueueTimeout returns the max waiting time of queued queries.
+func (q *Quota) GetQueueTimeout() (time.Duration, error) {
+	if len(q.QueueTimeout) < 1 {
+		return time.Second, nil
+	}
+	timeout, err := time.ParseDuration(q.QueueTimeout)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid queue timeout '%s'", q.QueueTimeout)
+	}
+	return timeout, nil
+}
+
 func (d *Node) String() string {
 	b, _ := json.Marshal(d)
 	return string(b)
@@ -178,17 +178,52 @@ func (s *storeOperate) Save(key config.PathKey, val []byte) error {
 		DataId:  string(key),
 		Content: string(val),
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	s.cfgLock.Lock()
+	s.confMap[key] = string(val)
+	s.cfgLock.Unlock()
+
+	return nil
 }
 
 //Get get a configuration
 func (s *storeOperate) Get(key config.PathKey) ([]byte, error) {
-	defer s.cfgLock.RUnlock()
 	s.cfgLock.RLock()
+	val, ok := s.confMap[key]
+	s.cfgLock.RUnlock()
+
+	if ok {
+		return []byte(val), nil
+	}
+
+	// keys which are not preloaded, eg: history versions
+	data, err := s.client.GetConfig(vo.ConfigParam{
+		DataId: string(key),
+		Group:  s.groupName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// Delete deletes a configuration
+func (s *storeOperate) Delete(key config.PathKey) error {
+	if _, err := s.client.DeleteConfig(vo.ConfigParam{
+		DataId: string(key),
+		Group:  s.groupName,
+	}); err != nil {
+		return err
+	}
 
-	val := []byte(s.confMap[key])
-	return val, nil
+	s.cfgLock.Lock()
+	delete(s.confMap, key)
+	s.cfgLock.Unlock()
+
+	return nil
 }
 
 //Watch Monitor changes of the key
@@ -0,0 +1,189 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+var _secretRefRegexp = regexp.MustCompile(`\$\{(env|file|enc):([^}]*)}`)
+
+// SecretResolver resolves the secret references in configuration values:
+//   ${env:NAME}  the value of environment variable NAME
+//   ${file:PATH} the content of file PATH, trailing newlines are trimmed
+//   ${enc:BLOB}  the AES-GCM encrypted blob in base64, decrypted by the secret key
+type SecretResolver struct {
+	key []byte
+}
+
+// NewSecretResolver creates a SecretResolver, the AES key is derived from the given secret key.
+func NewSecretResolver(secretKey string) *SecretResolver {
+	r := &SecretResolver{}
+	if len(secretKey) > 0 {
+		sum := sha256.Sum256([]byte(secretKey))
+		r.key = sum[:]
+	}
+	return r
+}
+
+// Resolve replaces all secret references in s.
+func (r *SecretResolver) Resolve(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var err error
+	ret := _secretRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		var (
+			matches = _secretRefRegexp.FindStringSubmatch(ref)
+			val     string
+		)
+		switch matches[1] {
+		case "env":
+			var ok bool
+			if val, ok = os.LookupEnv(matches[2]); !ok {
+				err = errors.Errorf("no such environment variable '%s'", matches[2])
+			}
+		case "file":
+			var b []byte
+			if b, err = ioutil.ReadFile(matches[2]); err != nil {
+				err = errors.Wrapf(err, "failed to read secret file '%s'", matches[2])
+			}
+			val = strings.TrimRight(string(b), "\r\n")
+		case "enc":
+			val, err = r.decrypt(matches[2])
+		}
+		return val
+	})
+	if err != nil {
+		return "", err
+	}
+	return ret, nil
+}
+
+// Encrypt encrypts the plaintext, returns the reference which can be resolved later.
+func (r *SecretResolver) Encrypt(plaintext string) (string, error) {
+	gcm, err := r.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	blob := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "${enc:" + base64.StdEncoding.EncodeToString(blob) + "}", nil
+}
+
+func (r *SecretResolver) decrypt(blob string) (string, error) {
+	gcm, err := r.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encrypted secret")
+	}
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted secret")
+	}
+
+	plaintext, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt secret")
+	}
+	return string(plaintext), nil
+}
+
+func (r *SecretResolver) gcm() (cipher.AEAD, error) {
+	if len(r.key) < 1 {
+		return nil, errors.New("no secret key supplied")
+	}
+	block, err := aes.NewCipher(r.key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return gcm, nil
+}
+
+// resolveConfiguration resolves the secret references of listeners, nodes and users in place.
+func (r *SecretResolver) resolveConfiguration(cfg *Configuration) error {
+	if cfg.Data == nil {
+		return nil
+	}
+
+	var err error
+	resolve := func(s *string) {
+		if err == nil {
+			*s, err = r.Resolve(*s)
+		}
+	}
+
+	for _, listener := range cfg.Data.Listeners {
+		if resolve(&listener.AdminToken); err != nil {
+			return errors.Wrapf(err, "failed to resolve admin token of listener %s", listener.ProtocolType)
+		}
+	}
+
+	for _, cluster := range cfg.Data.DataSourceClusters {
+		for _, group := range cluster.Groups {
+			for _, node := range group.Nodes {
+				resolve(&node.Host)
+				resolve(&node.Username)
+				resolve(&node.Password)
+				resolve(&node.Database)
+				if err != nil {
+					return errors.Wrapf(err, "failed to resolve node %s.%s.%s", cluster.Name, group.Name, node.Name)
+				}
+			}
+		}
+	}
+
+	for _, tenant := range cfg.Data.Tenants {
+		for _, user := range tenant.Users {
+			if resolve(&user.Password); err != nil {
+				return errors.Wrapf(err, "failed to resolve user %s of tenant %s", user.Username, tenant.Name)
+			}
+		}
+	}
+
+	return nil
+}
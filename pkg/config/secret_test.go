@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arana-secret")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("from_file\n"), 0600))
+	assert.NoError(t, os.Setenv("ARANA_FAKE_SECRET", "from_env"))
+	defer os.Unsetenv("ARANA_FAKE_SECRET")
+
+	r := NewSecretResolver("fake_key")
+
+	blob, err := r.Encrypt("from_blob")
+	assert.NoError(t, err)
+
+	for _, it := range []struct {
+		input, expect string
+	}{
+		{"plain", "plain"},
+		{"${env:ARANA_FAKE_SECRET}", "from_env"},
+		{"${file:" + path + "}", "from_file"},
+		{blob, "from_blob"},
+		{"user_${env:ARANA_FAKE_SECRET}_${0000...0007}", "user_from_env_${0000...0007}"},
+	} {
+		actual, err := r.Resolve(it.input)
+		assert.NoError(t, err)
+		assert.Equal(t, it.expect, actual)
+	}
+
+	_, err = r.Resolve("${env:ARANA_NO_SUCH_SECRET}")
+	assert.Error(t, err)
+	_, err = NewSecretResolver("wrong_key").Resolve(blob)
+	assert.Error(t, err)
+	_, err = NewSecretResolver("").Resolve(blob)
+	assert.Error(t, err)
+}
+
+func TestCenterResolveSecrets(t *testing.T) {
+	assert.NoError(t, os.Setenv("ARANA_FAKE_SECRET", "from_env"))
+	defer os.Unsetenv("ARANA_FAKE_SECRET")
+
+	cfg, err := LoadV2(FakeConfigPath)
+	assert.NoError(t, err)
+	cfg.Data.Tenants[0].Users[0].Password = "${env:ARANA_FAKE_SECRET}"
+	cfg.Data.Listeners[0].AdminToken = "${env:ARANA_FAKE_SECRET}"
+
+	store := make(fakeStore)
+	c := &Center{storeOperate: store}
+	assert.NoError(t, c.ImportConfiguration(cfg))
+
+	resolved, err := c.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "from_env", resolved.Data.Tenants[0].Users[0].Password)
+	assert.Equal(t, "from_env", resolved.Data.Listeners[0].AdminToken)
+
+	raw, err := c.LoadRaw()
+	assert.NoError(t, err)
+	assert.Equal(t, "${env:ARANA_FAKE_SECRET}", raw.Data.Tenants[0].Users[0].Password)
+
+	// secrets should never be persisted
+	assert.Contains(t, string(store[DefaultConfigDataTenantsPath]), "${env:ARANA_FAKE_SECRET}")
+	assert.NotContains(t, string(store[DefaultConfigDataTenantsPath]), "from_env")
+}
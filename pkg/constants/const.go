@@ -20,4 +20,7 @@ package constants
 const (
 	ConfigPathKey       = "config"
 	ImportConfigPathKey = "source"
+	ExportConfigPathKey = "output"
+	ConfigAuthorKey     = "author"
+	ConfigVersionKey    = "version"
 )
@@ -18,5 +18,6 @@
 package constants
 
 const (
-	EnvAranaConfig = "Arana_Config"
+	EnvAranaConfig    = "Arana_Config"
+	EnvAranaSecretKey = "Arana_Secret_Key"
 )
@@ -55,6 +55,13 @@ const (
 	// MysqlClearPassword transmits the password in the clear.
 	MysqlClearPassword = "mysql_clear_password"
 
+	// CachingSha2Password uses a salt and transmits a SHA256 hash on the wire,
+	// the full authentication requires TLS or RSA encryption.
+	CachingSha2Password = "caching_sha2_password"
+
+	// Sha256Password transmits the password over TLS, or encrypted by RSA.
+	Sha256Password = "sha256_password"
+
 	// MysqlDialog uses the dialog plugin on the client side.
 	// It transmits data in the clear.
 	MysqlDialog = "dialog"
@@ -253,6 +260,9 @@ const (
 	// AuthSwitchRequestPacket is used to switch auth method.
 	AuthSwitchRequestPacket = 0xfe
 
+	// AuthMoreDataPacket is used to send extra data of auth method.
+	AuthMoreDataPacket = 0x01
+
 	// ErrPacket is the header of the error packet.
 	ErrPacket = 0xff
 
@@ -422,11 +432,13 @@ const (
 	ERNoDefault                     = 1230
 	EROperandColumns                = 1241
 	ERSubqueryNo1Row                = 1242
+	ERUnknownStmtHandler            = 1243
 	ERWarnDataOutOfRange            = 1264
 	ERNonUpdateableTable            = 1288
 	ERFeatureDisabled               = 1289
 	EROptionPreventsStatement       = 1290
 	ERDuplicatedValueInType         = 1291
+	ERStmtHasNoOpenCursor           = 1421
 	ERRowIsReferenced2              = 1451
 	ErNoReferencedRow2              = 1452
 
@@ -540,6 +552,7 @@ const (
 	ERTruncatedWrongValueForField  = 1366
 	ERDataTooLong                  = 1406
 	ERDataOutOfRange               = 1690
+	ERQueryTimeout                 = 3024
 )
 
 // Sql states for errors.
@@ -557,6 +570,9 @@ const (
 	// SSHandshakeError is ER_HANDSHAKE_ERROR
 	SSHandshakeError = "08S01"
 
+	// SSConCount is ER_CON_COUNT_ERROR
+	SSConCount = "08004"
+
 	// SSServerShutdown is ER_SERVER_SHUTDOWN
 	SSServerShutdown = "08S01"
 
@@ -584,17 +600,38 @@ const (
 
 	// SSLockDeadlock is ER_LOCK_DEADLOCK
 	SSLockDeadlock = "40001"
+
+	// SSSyntaxErrorOrAccessViolation is ER_SPECIFIC_ACCESS_DENIED_ERROR
+	SSSyntaxErrorOrAccessViolation = "42000"
 )
 
 // Status flags. They are returned by the server in a few cases.
 // Originally found in include/mysql/mysql_com.h
 // See http://dev.mysql.com/doc/internals/en/status-flags.html
 const (
+	// ServerStatusInTrans is SERVER_STATUS_IN_TRANS.
+	ServerStatusInTrans = 0x0001
+
 	// ServerStatusAutocommit is SERVER_STATUS_AUTOCOMMIT.
 	ServerStatusAutocommit = 0x0002
 
 	// ServerMoreResultsExists is SERVER_MORE_RESULTS_EXISTS
 	ServerMoreResultsExists = 0x0008
+
+	// ServerStatusCursorExists is SERVER_STATUS_CURSOR_EXISTS.
+	ServerStatusCursorExists = 0x0040
+
+	// ServerStatusLastRowSent is SERVER_STATUS_LAST_ROW_SENT.
+	ServerStatusLastRowSent = 0x0080
+)
+
+// Cursor types of COM_STMT_EXECUTE.
+const (
+	// CursorTypeNoCursor is CURSOR_TYPE_NO_CURSOR.
+	CursorTypeNoCursor = 0x00
+
+	// CursorTypeReadOnly is CURSOR_TYPE_READ_ONLY.
+	CursorTypeReadOnly = 0x01
 )
 
 // A few interesting character set values.
@@ -31,10 +31,15 @@ import (
 )
 
 import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
 	"github.com/arana-db/arana/pkg/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/quota"
 	"github.com/arana-db/arana/pkg/runtime"
 	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -85,21 +90,11 @@ func (executor *RedirectExecutor) InGlobalTransaction(ctx *proto.Context) bool {
 }
 
 func (executor *RedirectExecutor) ExecuteUseDB(ctx *proto.Context) error {
-	// TODO: check permission, target database should belong to same tenant.
+	// the permission is checked by listener, just make sure the logical database is loaded.
 	// TODO: process transactions when database switched?
-
-	// do nothing.
-	//resourcePool := resource.GetDataSourceManager().GetMasterResourcePool(executor.dataSources[0].Master.Name)
-	//r, err := resourcePool.Get(ctx)
-	//defer func() {
-	//	resourcePool.Put(r)
-	//}()
-	//if err != nil {
-	//	return err
-	//}
-	//backendConn := r.(*mysql.BackendConnection)
-	//db := string(ctx.Data[1:])
-	//return backendConn.WriteComInitDB(db)
+	if _, err := runtime.Load(ctx.Schema); err != nil {
+		return err2.NewSQLError(consts.ERBadDb, "", "Unknown database '%s'", ctx.Schema)
+	}
 	return nil
 }
 
@@ -121,19 +116,42 @@ func (executor *RedirectExecutor) ExecuteFieldList(ctx *proto.Context) ([]proto.
 	return db.CallFieldList(ctx.Context, table, wildcard)
 }
 
-func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Result, uint16, error) {
-	var err error
-
-	p := parser.New()
+func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context) (res proto.Result, warn uint16, err error) {
 	query := ctx.GetQuery()
-	act, err := p.ParseOneStmt(query, "", "")
-	if err != nil {
-		return nil, 0, err
+
+	ctx.Context = slowlog.Begin(ctx.Context, ctx.Tenant, ctx.Username, ctx.RemoteAddr, query)
+	defer func() {
+		executor.doPostFilter(ctx, res, err)
+		slowlog.FromContext(ctx.Context).Finish(rowsOf(res), err)
+	}()
+
+	// the statement may be parsed already, eg: one of multiple statements
+	if ctx.Stmt == nil {
+		_, span := trace.Start(ctx.Context, "parse")
+		act, err := parser.New().ParseOneStmt(query, "", "")
+		trace.End(span, err)
+		if err != nil {
+			return nil, 0, err
+		}
+		log.Debugf("ComQuery: %s", query)
+
+		ctx.Stmt = &proto.Stmt{
+			StmtNode: act,
+		}
 	}
-	log.Debugf("ComQuery: %s", query)
 
-	ctx.Stmt = &proto.Stmt{
-		StmtNode: act,
+	if res, err = executor.doPreFilter(ctx); err != nil || res != nil {
+		return res, 0, err
+	}
+	// the statement may be rewritten by pre filters
+	act := ctx.Stmt.StmtNode
+
+	if !isTxControl(act) {
+		release, err := quota.Acquire(ctx.Context, ctx.Tenant, ctx.Username)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer release()
 	}
 
 	rt, err := runtime.Load(ctx.Schema)
@@ -141,16 +159,14 @@ func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Re
 		return nil, 0, err
 	}
 
-	var (
-		res  proto.Result
-		warn uint16
-	)
-
-	executor.doPreFilter(ctx)
-
-	switch act.(type) {
+	switch stmt := act.(type) {
 	case *ast.BeginStmt:
-		// begin a new tx
+		// commit the current tx implicitly, then begin a new tx
+		if tx, ok := executor.removeTx(ctx); ok {
+			if _, _, err = tx.Commit(ctx.Context); err != nil {
+				return nil, 0, err
+			}
+		}
 		var tx proto.Tx
 		if tx, err = rt.Begin(ctx); err == nil {
 			executor.putTx(ctx, tx)
@@ -171,12 +187,25 @@ func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Re
 			res, warn, err = nil, 0, errMissingTx
 		}
 	case *ast.SelectStmt, *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+		cancel := withTimeout(ctx, rt, stmt)
+		defer cancel()
+
 		// TODO: merge with other stmt when write-mode is supported for runtime
-		if tx, ok := executor.getTx(ctx); ok {
+		var (
+			tx proto.Tx
+			ok bool
+		)
+		if tx, ok, err = executor.implicitTx(ctx, rt); err != nil {
+			return nil, 0, timeoutError(ctx.Context, err)
+		}
+		if ok {
 			res, warn, err = tx.Execute(ctx)
 		} else {
 			res, warn, err = rt.Execute(ctx)
 		}
+		err = timeoutError(ctx.Context, err)
+	case *ast.SetStmt:
+		res, warn, err = executor.handleSet(ctx, rt, stmt)
 	case *ast.ShowStmt:
 		res, warn, err = rt.Execute(ctx)
 	case *ast.TruncateTableStmt:
@@ -191,42 +220,63 @@ func (executor *RedirectExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Re
 		}
 	}
 
-	executor.doPostFilter(ctx, res)
-
 	return res, warn, err
 }
 
-func (executor *RedirectExecutor) ExecutorComStmtExecute(ctx *proto.Context) (proto.Result, uint16, error) {
-	var (
-		executable proto.Executable
-		result     proto.Result
-		warn       uint16
-		err        error
-	)
-
-	if tx, ok := executor.getTx(ctx); ok {
-		executable = tx
-	} else {
-		var rt runtime.Runtime
-		if rt, err = runtime.Load(ctx.Schema); err != nil {
+func (executor *RedirectExecutor) ExecutorComStmtExecute(ctx *proto.Context) (result proto.Result, warn uint16, err error) {
+	var executable proto.Executable
+
+	ctx.Context = slowlog.Begin(ctx.Context, ctx.Tenant, ctx.Username, ctx.RemoteAddr, ctx.Stmt.StmtNode.Text())
+	defer func() {
+		executor.doPostFilter(ctx, result, err)
+		slowlog.FromContext(ctx.Context).Finish(rowsOf(result), err)
+	}()
+
+	if result, err = executor.doPreFilter(ctx); err != nil || result != nil {
+		return result, 0, err
+	}
+
+	if !isTxControl(ctx.Stmt.StmtNode) {
+		release, err := quota.Acquire(ctx.Context, ctx.Tenant, ctx.Username)
+		if err != nil {
 			return nil, 0, err
 		}
-		executable = rt
+		defer release()
 	}
 
-	switch ctx.Stmt.StmtNode.(type) {
+	rt, err := runtime.Load(ctx.Schema)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch stmt := ctx.Stmt.StmtNode.(type) {
 	case *ast.SelectStmt, *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+		cancel := withTimeout(ctx, rt, stmt)
+		defer cancel()
+
+		// begin a tx implicitly if autocommit is off
+		tx, ok, err := executor.implicitTx(ctx, rt)
+		if err != nil {
+			return nil, 0, timeoutError(ctx.Context, err)
+		}
+		if ok {
+			executable = tx
+		}
 	default:
 		ctx.Context = rcontext.WithDirect(ctx.Context)
+		if tx, ok := executor.getTx(ctx); ok {
+			executable = tx
+		}
+	}
+	if executable == nil {
+		executable = rt
 	}
 
 	query := ctx.Stmt.StmtNode.Text()
 	log.Debugf(query)
 
-	executor.doPreFilter(ctx)
 	result, warn, err = executable.Execute(ctx)
-	executor.doPostFilter(ctx, result)
-	return result, warn, err
+	return result, warn, timeoutError(ctx.Context, err)
 }
 
 func (executor *RedirectExecutor) ConnectionClose(ctx *proto.Context) {
@@ -252,6 +302,14 @@ func (executor *RedirectExecutor) ConnectionClose(ctx *proto.Context) {
 	//}
 }
 
+// rowsOf returns the amount of rows returned by result.
+func rowsOf(res proto.Result) int {
+	if res == nil {
+		return 0
+	}
+	return len(res.GetRows())
+}
+
 func (executor *RedirectExecutor) putTx(ctx *proto.Context, tx proto.Tx) {
 	executor.localTransactionMap.Store(ctx.ConnectionID, tx)
 }
@@ -272,30 +330,47 @@ func (executor *RedirectExecutor) getTx(ctx *proto.Context) (proto.Tx, bool) {
 	return exist.(proto.Tx), true
 }
 
-func (executor *RedirectExecutor) doPreFilter(ctx *proto.Context) {
-	for i := 0; i < len(executor.preFilters); i++ {
-		func(ctx *proto.Context) {
-			defer func() {
-				if err := recover(); err != nil {
-					log.Errorf("failed to execute filter: %s, err: %v", executor.preFilters[i].GetName(), err)
-				}
-			}()
-			filter := executor.preFilters[i]
-			filter.PreHandle(ctx)
-		}(ctx)
+// doPreFilter runs the pre filters in order, it stops at the first filter which rejects or short-circuits the statement.
+func (executor *RedirectExecutor) doPreFilter(ctx *proto.Context) (result proto.Result, err error) {
+	for _, filter := range executor.preFilters {
+		if result, err = preHandle(filter, ctx); err != nil || result != nil {
+			return
+		}
 	}
+	return nil, nil
+}
+
+// preHandle calls the pre filter, the statement will be rejected if the filter panics.
+func preHandle(filter proto.PreFilter, ctx *proto.Context) (result proto.Result, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Errorf("failed to execute filter: %s, err: %v", filter.GetName(), rec)
+			result, err = nil, errors.Errorf("failed to execute filter %s: %v", filter.GetName(), rec)
+		}
+	}()
+	return filter.PreHandle(ctx)
+}
+
+// isTxControl returns true if the statement begins or ends a transaction. They are not limited by quota,
+// otherwise the transactions holding backend connections cannot be ended once the quota is exceeded.
+func isTxControl(stmt ast.StmtNode) bool {
+	switch stmt.(type) {
+	case *ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt:
+		return true
+	}
+	return false
 }
 
-func (executor *RedirectExecutor) doPostFilter(ctx *proto.Context, result proto.Result) {
+func (executor *RedirectExecutor) doPostFilter(ctx *proto.Context, result proto.Result, err error) {
 	for i := 0; i < len(executor.postFilters); i++ {
 		func(ctx *proto.Context) {
 			defer func() {
-				if err := recover(); err != nil {
-					log.Errorf("failed to execute filter: %s, err: %v", executor.postFilters[i].GetName(), err)
+				if rec := recover(); rec != nil {
+					log.Errorf("failed to execute filter: %s, err: %v", executor.postFilters[i].GetName(), rec)
 				}
 			}()
 			filter := executor.postFilters[i]
-			filter.PostHandle(ctx, result)
+			filter.PostHandle(ctx, result, err)
 		}(ctx)
 	}
 }
@@ -18,6 +18,7 @@
 package executor
 
 import (
+	"context"
 	"testing"
 )
 
@@ -28,7 +29,12 @@ import (
 )
 
 import (
+	"github.com/arana-db/arana/pkg/config"
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/quota"
 )
 
 func TestIsErrMissingTx(t *testing.T) {
@@ -64,6 +70,39 @@ func TestGetPostFilters(t *testing.T) {
 	assert.Equal(t, "PostFilterTest", redirect.GetPostFilters()[0].GetName())
 }
 
+func TestDoPreFilter(t *testing.T) {
+	var called []string
+	newFilter := func(name string, result proto.Result, err error) proto.PreFilter {
+		return &funcPreFilter{name: name, fn: func(*proto.Context) (proto.Result, error) {
+			called = append(called, name)
+			return result, err
+		}}
+	}
+
+	redirect := NewRedirectExecutor()
+	redirect.AddPreFilter(newFilter("pass", nil, nil))
+	redirect.AddPreFilter(newFilter("short-circuit", &mysql.Result{AffectedRows: 1}, nil))
+	redirect.AddPreFilter(newFilter("reject", nil, errors.New("denied")))
+	res, err := redirect.doPreFilter(createContext())
+	assert.NoError(t, err)
+	assert.Equal(t, &mysql.Result{AffectedRows: 1}, res)
+	assert.Equal(t, []string{"pass", "short-circuit"}, called)
+
+	called = called[:0]
+	redirect = NewRedirectExecutor()
+	redirect.AddPreFilter(newFilter("reject", nil, errors.New("denied")))
+	redirect.AddPreFilter(newFilter("pass", nil, nil))
+	_, err = redirect.doPreFilter(createContext())
+	assert.EqualError(t, err, "denied")
+	assert.Equal(t, []string{"reject"}, called)
+
+	// a panic filter rejects the statement
+	redirect = NewRedirectExecutor()
+	redirect.AddPreFilter(&PreFilterTest{})
+	_, err = redirect.doPreFilter(createContext())
+	assert.Error(t, err)
+}
+
 func TestProcessDistributedTransaction(t *testing.T) {
 	redirect := NewRedirectExecutor()
 	assert.False(t, redirect.ProcessDistributedTransaction())
@@ -80,6 +119,45 @@ func TestInLocalTransaction(t *testing.T) {
 	assert.False(t, result)
 }
 
+func TestQuotaExemptTxControl(t *testing.T) {
+	const tenant = "fake_quota_tenant"
+	assert.NoError(t, quota.Put(tenant, "", &config.Quota{MaxConcurrency: 1}))
+	defer quota.RemoveTenant(tenant)
+
+	// exhaust the quota
+	release, err := quota.Acquire(context.Background(), tenant, "foo")
+	assert.NoError(t, err)
+	defer release()
+
+	newContext := func(sql string) *proto.Context {
+		ctx := createContext()
+		ctx.Context = context.Background()
+		ctx.Tenant, ctx.Username = tenant, "foo"
+		ctx.Data = append([]byte{consts.ComQuery}, sql...)
+		return ctx
+	}
+
+	redirect := NewRedirectExecutor()
+	_, _, err = redirect.ExecutorComQuery(newContext("select 1"))
+	if assert.IsType(t, (*err2.SQLError)(nil), err) {
+		assert.Equal(t, consts.ERUserLimitReached, err.(*err2.SQLError).Num)
+	}
+
+	// the transactions can be ended even if the quota is exceeded, it fails for no runtime only
+	for _, sql := range []string{"begin", "commit", "rollback"} {
+		_, _, err = redirect.ExecutorComQuery(newContext(sql))
+		assert.Error(t, err)
+		assert.NotEqual(t, consts.ERUserLimitReached, sqlErrorNum(err), sql)
+	}
+}
+
+func sqlErrorNum(err error) int {
+	if se, ok := err.(*err2.SQLError); ok {
+		return se.Num
+	}
+	return 0
+}
+
 func createContext() *proto.Context {
 	result := &proto.Context{
 		ConnectionID: 0,
@@ -97,6 +175,19 @@ func (filter *PreFilterTest) GetName() string {
 	return "PreFilterTest"
 }
 
+type funcPreFilter struct {
+	name string
+	fn   func(ctx *proto.Context) (proto.Result, error)
+}
+
+func (filter *funcPreFilter) GetName() string {
+	return filter.name
+}
+
+func (filter *funcPreFilter) PreHandle(ctx *proto.Context) (proto.Result, error) {
+	return filter.fn(ctx)
+}
+
 type PostFilterTest struct {
 	proto.PostFilter
 }
@@ -0,0 +1,324 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executor
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/arana-db/parser/ast"
+	"github.com/arana-db/parser/format"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+)
+
+const (
+	_varAutocommit       = "autocommit"
+	_varMaxExecutionTime = "max_execution_time"
+)
+
+// _varAliases are the deprecated names of system variables, which are removed since MySQL 8.0.
+var _varAliases = map[string]string{
+	"tx_isolation": "transaction_isolation",
+	"tx_read_only": "transaction_read_only",
+}
+
+// handleSet handles SET statement. The variables are tracked by session and replayed on backend connections,
+// except that autocommit is implemented by implicit transactions, and max_execution_time is enforced by proxy.
+func (executor *RedirectExecutor) handleSet(ctx *proto.Context, rt runtime.Runtime, stmt *ast.SetStmt) (proto.Result, uint16, error) {
+	session := rcontext.Session(ctx.Context)
+	if session == nil {
+		return nil, 0, errors.New("no session found")
+	}
+
+	var (
+		conn  = executor.vconnOf(ctx, rt)
+		names = make([]string, 0, len(stmt.Variables))
+		exprs []ast.ExprNode // the expressions to be evaluated by backend
+		evals []int          // the indexes of names of evaluated expressions
+		vars  = make([]string, len(stmt.Variables))
+
+		autocommit       *bool
+		txIsolation      *string
+		maxExecutionTime *time.Duration
+		probe            bool
+	)
+
+	for i, it := range stmt.Variables {
+		if it.IsGlobal {
+			return nil, 0, err2.NewSQLError(consts.ERSpecifiedAccessDenied, consts.SSSyntaxErrorOrAccessViolation,
+				"Access denied; SET GLOBAL is not supported")
+		}
+
+		var name string
+		switch {
+		case it.Name == ast.SetNames:
+			name = proto.VarNames
+		case it.Name == ast.SetCharset:
+			name = proto.VarCharset
+		case it.IsSystem:
+			name = strings.ToLower(it.Name)
+			if alias, ok := _varAliases[name]; ok {
+				name = alias
+			}
+			name = "@@" + name
+		default:
+			name = "@" + strings.ToLower(it.Name)
+		}
+		names = append(names, name)
+
+		if name == proto.VarNames || name == proto.VarCharset {
+			probe = true
+			if _, ok := it.Value.(*ast.DefaultExpr); ok {
+				continue
+			}
+			value, err := restore(it.Value)
+			if err != nil {
+				return nil, 0, err
+			}
+			if it.ExtendValue != nil {
+				collation, err := restore(it.ExtendValue)
+				if err != nil {
+					return nil, 0, err
+				}
+				value += " COLLATE " + collation
+			}
+			vars[i] = value
+			continue
+		}
+
+		if strings.HasPrefix(name, "@@") {
+			probe = true
+		}
+
+		switch v := it.Value.(type) {
+		case *ast.DefaultExpr:
+			// empty value means resetting to default
+		case ast.ValueExpr:
+			// rebuild the value to drop the charset introducer, eg: _utf8mb4'READ-COMMITTED'
+			value, err := restore(ast.NewValueExpr(v.GetValue(), "", ""))
+			if err != nil {
+				return nil, 0, err
+			}
+			vars[i] = value
+		case *ast.ColumnNameExpr:
+			// the identifier is used as string, eg: SET time_zone = SYSTEM
+			value, err := restore(ast.NewValueExpr(v.Name.Name.O, "", ""))
+			if err != nil {
+				return nil, 0, err
+			}
+			vars[i] = value
+		default:
+			exprs = append(exprs, v)
+			evals = append(evals, i)
+		}
+	}
+
+	// evaluate the expressions by backend with current session variables, eg: SET @a = @a + 1
+	if len(exprs) > 0 {
+		values, err := evaluate(ctx, conn, exprs)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i, it := range evals {
+			vars[it] = values[i]
+		}
+	}
+
+	for i, name := range names {
+		switch name {
+		case "@@" + _varAutocommit:
+			on, err := parseSwitch(vars[i], true)
+			if err != nil {
+				return nil, 0, err2.NewSQLError(consts.ERWrongValueForVar, consts.SSSyntaxErrorOrAccessViolation,
+					"Variable '%s' can't be set to the value of '%s'", _varAutocommit, vars[i])
+			}
+			autocommit = &on
+		case "@@tx_isolation_one_shot":
+			level := strings.ReplaceAll(strings.Trim(vars[i], "'"), "-", " ")
+			txIsolation = &level
+		case "@@" + _varMaxExecutionTime:
+			// in milliseconds, just like MySQL
+			var millis uint64
+			if len(vars[i]) > 0 {
+				var err error
+				if millis, err = strconv.ParseUint(vars[i], 10, 32); err != nil {
+					return nil, 0, err2.NewSQLError(consts.ERWrongTypeForVar, consts.SSSyntaxErrorOrAccessViolation,
+						"Incorrect argument type to variable '%s'", _varMaxExecutionTime)
+				}
+			}
+			timeout := time.Duration(millis) * time.Millisecond
+			maxExecutionTime = &timeout
+		}
+	}
+
+	prev := session.Variables()
+	for i, name := range names {
+		switch name {
+		case "@@" + _varAutocommit, "@@tx_isolation_one_shot", "@@" + _varMaxExecutionTime:
+			continue
+		}
+		session.SetVariable(name, vars[i])
+	}
+
+	// check the system variables by replaying them on a backend connection
+	if probe {
+		if _, err := conn.Query(ctx.Context, "", "SELECT 1"); err != nil {
+			session.SetVariables(prev)
+			return nil, 0, err
+		}
+	}
+
+	if txIsolation != nil {
+		session.SetNextTxIsolation(*txIsolation)
+	}
+	if maxExecutionTime != nil {
+		session.SetMaxExecutionTime(*maxExecutionTime)
+	}
+
+	if autocommit != nil {
+		// commit the current transaction if autocommit is changed from 0 to 1
+		if *autocommit && !session.IsAutocommit() {
+			if tx, ok := executor.removeTx(ctx); ok {
+				if _, _, err := tx.Commit(ctx.Context); err != nil {
+					return nil, 0, err
+				}
+			}
+		}
+		session.SetAutocommit(*autocommit)
+	}
+
+	return &mysql.Result{}, 0, nil
+}
+
+// implicitTx returns the current transaction, a new transaction will be began if autocommit is off.
+func (executor *RedirectExecutor) implicitTx(ctx *proto.Context, rt runtime.Runtime) (proto.Tx, bool, error) {
+	if tx, ok := executor.getTx(ctx); ok {
+		return tx, true, nil
+	}
+	if rcontext.Session(ctx.Context).IsAutocommit() {
+		return nil, false, nil
+	}
+	tx, err := rt.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	executor.putTx(ctx, tx)
+	return tx, true, nil
+}
+
+// vconnOf returns the current transaction if exists, or the runtime.
+func (executor *RedirectExecutor) vconnOf(ctx *proto.Context, rt runtime.Runtime) proto.VConn {
+	if tx, ok := executor.getTx(ctx); ok {
+		if conn, ok := tx.(proto.VConn); ok {
+			return conn
+		}
+	}
+	return rt.(proto.VConn)
+}
+
+// evaluate evaluates the expressions by backend, and returns their values in SQL literal.
+func evaluate(ctx *proto.Context, conn proto.VConn, exprs []ast.ExprNode) ([]string, error) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for i, it := range exprs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		s, err := restore(it)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(s)
+	}
+
+	res, err := conn.Query(ctx.Context, "", sb.String())
+	if err != nil {
+		return nil, err
+	}
+	rows := res.GetRows()
+	if len(rows) != 1 {
+		return nil, errors.Errorf("expect 1 row when evaluating variables, but got %d", len(rows))
+	}
+
+	row, ok := rows[0].(*mysql.Row)
+	if !ok {
+		return nil, errors.Errorf("unexpected row type %T when evaluating variables", rows[0])
+	}
+	values, err := (&mysql.TextRow{Row: *row}).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	literals := make([]string, 0, len(values))
+	for _, it := range values {
+		literal, err := literalOf(it)
+		if err != nil {
+			return nil, err
+		}
+		literals = append(literals, literal)
+	}
+	return literals, nil
+}
+
+// literalOf converts the value decoded from text row into SQL literal.
+func literalOf(v *proto.Value) (string, error) {
+	if v == nil || v.Val == nil {
+		return "NULL", nil
+	}
+	switch v.Typ {
+	case consts.FieldTypeTiny, consts.FieldTypeShort, consts.FieldTypeLong, consts.FieldTypeLongLong,
+		consts.FieldTypeInt24, consts.FieldTypeFloat, consts.FieldTypeDouble,
+		consts.FieldTypeDecimal, consts.FieldTypeNewDecimal:
+		return string(v.Raw), nil
+	}
+	return restore(ast.NewValueExpr(string(v.Raw), "", ""))
+}
+
+// parseSwitch parses the value of boolean variables like autocommit, empty value means default.
+func parseSwitch(value string, def bool) (bool, error) {
+	switch strings.ToLower(strings.Trim(value, "'")) {
+	case "":
+		return def, nil
+	case "1", "on", "true":
+		return true, nil
+	case "0", "off", "false":
+		return false, nil
+	}
+	return false, errors.Errorf("invalid switch value '%s'", value)
+}
+
+func restore(node ast.Node) (string, error) {
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestoreStringEscapeBackslash, &sb)); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return sb.String(), nil
+}
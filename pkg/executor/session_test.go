@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/arana-db/parser"
+	"github.com/arana-db/parser/ast"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+)
+
+// fakeRuntime answers the queries of SET statements, the probe will fail if the session contains '@@fail'.
+type fakeRuntime struct {
+	runtime.Runtime
+	queries []string
+	txs     []*fakeTx
+}
+
+func (f *fakeRuntime) Query(ctx context.Context, _ string, query string, _ ...interface{}) (proto.Result, error) {
+	f.queries = append(f.queries, query)
+	for _, it := range rcontext.Session(ctx).Variables() {
+		if it.Name == "@@fail" {
+			return nil, errors.New("unknown system variable 'fail'")
+		}
+	}
+	if query == "SELECT 1" {
+		return &mysql.Result{}, nil
+	}
+	// the evaluated value is always 2
+	return &mysql.Result{
+		Rows: []proto.Row{&mysql.Row{
+			Content:   []byte{1, '2'},
+			ResultSet: &mysql.ResultSet{Columns: []proto.Field{mysql.NewField("v")}},
+		}},
+	}, nil
+}
+
+func (f *fakeRuntime) Exec(context.Context, string, string, ...interface{}) (proto.Result, error) {
+	return &mysql.Result{}, nil
+}
+
+func (f *fakeRuntime) Begin(*proto.Context) (proto.Tx, error) {
+	tx := &fakeTx{}
+	f.txs = append(f.txs, tx)
+	return tx, nil
+}
+
+type fakeTx struct {
+	proto.Tx
+	committed bool
+}
+
+func (f *fakeTx) Commit(context.Context) (proto.Result, uint16, error) {
+	f.committed = true
+	return &mysql.Result{}, 0, nil
+}
+
+func TestHandleSet(t *testing.T) {
+	var (
+		executor = NewRedirectExecutor()
+		rt       = &fakeRuntime{}
+		session  = proto.NewSession()
+		ctx      = &proto.Context{Context: rcontext.WithSession(context.Background(), session), ConnectionID: 1}
+	)
+
+	set := func(sql string) error {
+		stmt, err := parser.New().ParseOneStmt(sql, "", "")
+		assert.NoError(t, err)
+		_, _, err = executor.handleSet(ctx, rt, stmt.(*ast.SetStmt))
+		return err
+	}
+
+	assert.NoError(t, set("SET NAMES utf8mb4 COLLATE utf8mb4_bin, sql_mode = 'STRICT_TRANS_TABLES', @A = 'it''s', time_zone = SYSTEM"))
+	assert.Equal(t, []proto.Variable{
+		{Name: proto.VarNames, Value: "'utf8mb4' COLLATE 'utf8mb4_bin'"},
+		{Name: "@@sql_mode", Value: "'STRICT_TRANS_TABLES'"},
+		{Name: "@a", Value: "'it''s'"},
+		{Name: "@@time_zone", Value: "'SYSTEM'"},
+	}, session.Variables())
+	assert.Equal(t, []string{"SELECT 1"}, rt.queries)
+
+	// the expressions are evaluated with current session, and the reassigned variable is moved to the last
+	rt.queries = nil
+	assert.NoError(t, set("SET @b = @a + 1, @@SESSION.sql_mode = DEFAULT"))
+	assert.NoError(t, set("SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED"))
+	assert.Equal(t, []proto.Variable{
+		{Name: proto.VarNames, Value: "'utf8mb4' COLLATE 'utf8mb4_bin'"},
+		{Name: "@a", Value: "'it''s'"},
+		{Name: "@@time_zone", Value: "'SYSTEM'"},
+		{Name: "@b", Value: "2"},
+	}, session.Variables()[:4])
+	assert.Equal(t, proto.Variable{Name: "@@transaction_isolation", Value: "'READ-COMMITTED'"}, session.Variables()[4])
+	assert.Len(t, rt.queries, 3)
+	assert.True(t, strings.HasPrefix(rt.queries[0], "SELECT @`a`+1"), rt.queries[0])
+
+	// the one-shot isolation level is used by next transaction only
+	assert.NoError(t, set("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"))
+	assert.Equal(t, "SERIALIZABLE", session.TakeNextTxIsolation())
+	assert.Empty(t, session.TakeNextTxIsolation())
+
+	// the variables are restored if the backend rejects them
+	prev := session.Variables()
+	assert.Error(t, set("SET @@fail = 1, @c = 1"))
+	assert.Equal(t, prev, session.Variables())
+	assert.Error(t, set("SET GLOBAL sql_mode = ''"))
+	assert.Equal(t, prev, session.Variables())
+
+	// autocommit is handled by implicit transactions
+	assert.Error(t, set("SET autocommit = 'maybe'"))
+	assert.NoError(t, set("SET autocommit = OFF"))
+	assert.False(t, session.IsAutocommit())
+	assert.Equal(t, prev, session.Variables())
+
+	tx, ok, err := executor.implicitTx(ctx, rt)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, executor.InLocalTransaction(ctx))
+
+	assert.NoError(t, set("SET autocommit = 1"))
+	assert.True(t, session.IsAutocommit())
+	assert.True(t, tx.(*fakeTx).committed)
+	assert.False(t, executor.InLocalTransaction(ctx))
+
+	_, ok, err = executor.implicitTx(ctx, rt)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// max_execution_time is enforced by proxy
+	assert.Error(t, set("SET max_execution_time = 'fast'"))
+	assert.NoError(t, set("SET max_execution_time = 1500"))
+	assert.Equal(t, 1500*time.Millisecond, session.MaxExecutionTime())
+	assert.Equal(t, prev, session.Variables())
+	assert.NoError(t, set("SET max_execution_time = DEFAULT"))
+	assert.Zero(t, session.MaxExecutionTime())
+}
+
+func TestParseSwitch(t *testing.T) {
+	for value, expect := range map[string]bool{"": true, "1": true, "'ON'": true, "TRUE": true, "0": false, "'off'": false, "FALSE": false} {
+		on, err := parseSwitch(value, true)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, on, value)
+	}
+	_, err := parseSwitch("2", true)
+	assert.Error(t, err)
+}
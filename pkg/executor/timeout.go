@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executor
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"github.com/arana-db/parser/ast"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+const _hintMaxExecutionTime = "max_execution_time"
+
+// withTimeout sets the deadline of statement, the queries on backends will be killed once it's exceeded.
+func withTimeout(ctx *proto.Context, rt runtime.Runtime, stmt ast.StmtNode) context.CancelFunc {
+	timeout := maxExecutionTime(ctx, rt, stmt)
+	if timeout <= 0 {
+		return func() {}
+	}
+	var cancel context.CancelFunc
+	ctx.Context, cancel = context.WithTimeout(ctx.Context, timeout)
+	return cancel
+}
+
+// maxExecutionTime returns the timeout of statement, zero means unlimited. The timeout is chosen in order:
+//  1. the MAX_EXECUTION_TIME hint of statement, eg: SELECT /*+ MAX_EXECUTION_TIME(1000) */ ...
+//  2. the session variable max_execution_time
+//  3. the smallest max_execution_time of tables in statement
+//  4. the max_execution_time of tenant
+func maxExecutionTime(ctx *proto.Context, rt runtime.Runtime, stmt ast.StmtNode) time.Duration {
+	if timeout, ok := hintMaxExecutionTime(stmt); ok {
+		return timeout
+	}
+	if timeout := rcontext.Session(ctx.Context).MaxExecutionTime(); timeout > 0 {
+		return timeout
+	}
+
+	var timeout time.Duration
+	if ru := rt.Namespace().Rule(); ru != nil {
+		var v tableVisitor
+		stmt.Accept(&v)
+		for _, it := range v.tables {
+			vt, ok := ru.VTable(it.Name.O)
+			if !ok {
+				continue
+			}
+			if t := vt.MaxExecutionTime(); t > 0 && (timeout == 0 || t < timeout) {
+				timeout = t
+			}
+		}
+	}
+	if timeout > 0 {
+		return timeout
+	}
+
+	return security.DefaultTenantManager().GetMaxExecutionTime(ctx.Tenant)
+}
+
+// hintMaxExecutionTime returns the timeout of MAX_EXECUTION_TIME hint, which is in milliseconds.
+func hintMaxExecutionTime(stmt ast.StmtNode) (time.Duration, bool) {
+	var hints []*ast.TableOptimizerHint
+	switch it := stmt.(type) {
+	case *ast.SelectStmt:
+		hints = it.TableHints
+	case *ast.InsertStmt:
+		hints = it.TableHints
+	case *ast.UpdateStmt:
+		hints = it.TableHints
+	case *ast.DeleteStmt:
+		hints = it.TableHints
+	}
+	for _, it := range hints {
+		if it.HintName.L != _hintMaxExecutionTime {
+			continue
+		}
+		if millis, ok := it.HintData.(uint64); ok {
+			return time.Duration(millis) * time.Millisecond, true
+		}
+	}
+	return 0, false
+}
+
+// timeoutError converts the error to ER_QUERY_TIMEOUT if the statement is timeout.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return err2.NewSQLError(consts.ERQueryTimeout, consts.SSUnknownSQLState,
+		"Query execution was interrupted, maximum statement execution time exceeded")
+}
+
+type tableVisitor struct {
+	tables []*ast.TableName
+}
+
+func (v *tableVisitor) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok {
+		v.tables = append(v.tables, t)
+	}
+	return n, false
+}
+
+func (v *tableVisitor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
@@ -0,0 +1,116 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+type fakeNamespaceRuntime struct {
+	runtime.Runtime
+	ns *namespace.Namespace
+}
+
+func (f *fakeNamespaceRuntime) Namespace() *namespace.Namespace {
+	return f.ns
+}
+
+func TestMaxExecutionTime(t *testing.T) {
+	const tenant = "fake_timeout_tenant"
+
+	var (
+		ru      rule.Rule
+		student rule.VTable
+		score   rule.VTable
+	)
+	student.SetMaxExecutionTime(3 * time.Second)
+	score.SetMaxExecutionTime(2 * time.Second)
+	ru.SetVTable("student", &student)
+	ru.SetVTable("score", &score)
+	ru.SetVTable("teacher", &rule.VTable{})
+
+	ns := namespace.New("fake_timeout_db", nil, namespace.UpdateRule(&ru))
+	defer ns.Close()
+
+	var (
+		rt      = &fakeNamespaceRuntime{ns: ns}
+		session = proto.NewSession()
+		ctx     = &proto.Context{Context: rcontext.WithSession(context.Background(), session), Tenant: tenant}
+	)
+
+	timeoutOf := func(sql string) time.Duration {
+		stmt, err := parser.New().ParseOneStmt(sql, "", "")
+		assert.NoError(t, err)
+		return maxExecutionTime(ctx, rt, stmt)
+	}
+
+	// unlimited by default
+	assert.Zero(t, timeoutOf("select * from teacher"))
+
+	security.DefaultTenantManager().SetMaxExecutionTime(tenant, 5*time.Second)
+	defer security.DefaultTenantManager().SetMaxExecutionTime(tenant, 0)
+	assert.Equal(t, 5*time.Second, timeoutOf("select * from teacher"))
+
+	// the smallest timeout of tables overrides tenant
+	assert.Equal(t, 3*time.Second, timeoutOf("update student set name = 'foo' where uid = 1"))
+	assert.Equal(t, 2*time.Second, timeoutOf("select * from student a join score b on a.uid = b.uid"))
+
+	// the session variable overrides tables
+	session.SetMaxExecutionTime(time.Second)
+	assert.Equal(t, time.Second, timeoutOf("select * from student"))
+
+	// the hint overrides all, and zero means unlimited
+	assert.Equal(t, 100*time.Millisecond, timeoutOf("select /*+ MAX_EXECUTION_TIME(100) */ * from student"))
+	assert.Zero(t, timeoutOf("select /*+ MAX_EXECUTION_TIME(0) */ * from student"))
+}
+
+func TestTimeoutError(t *testing.T) {
+	assert.NoError(t, timeoutError(context.Background(), nil))
+
+	fake := errors.New("fake error")
+	assert.Equal(t, fake, timeoutError(context.Background(), fake))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	err := timeoutError(ctx, fake)
+	if assert.IsType(t, (*err2.SQLError)(nil), err) {
+		assert.Equal(t, consts.ERQueryTimeout, err.(*err2.SQLError).Num)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package audit provides the audit filter, which records every statement as JSON lines.
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	filter "github.com/arana-db/arana/pkg/filters"
+	"github.com/arana-db/arana/pkg/metrics"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/util/log"
+	"github.com/arana-db/arana/pkg/util/rand2"
+)
+
+// Name is the name of audit filter.
+const Name = "audit"
+
+var (
+	_ proto.FilterFactory = (*factory)(nil)
+	_ proto.PostFilter    = (*auditFilter)(nil)
+)
+
+func init() {
+	filter.RegistryFilterFactory(Name, &factory{})
+}
+
+// Config represents the config of audit filter.
+type Config struct {
+	// Sink is the name of sink, default is file.
+	Sink string `json:"sink"`
+	// Path is the output file of file sink.
+	Path string `json:"path"`
+	// MaxSize is the max size in megabytes of file sink before it gets rotated, default is 100.
+	MaxSize int `json:"max_size"`
+	// MaxBackups is the max number of old files of file sink to retain, default is 10.
+	MaxBackups int `json:"max_backups"`
+	// MaxAge is the max days to retain old files of file sink, default is 30.
+	MaxAge int `json:"max_age"`
+	// Include is the statement types to record, eg: select, insert, all types will be recorded if empty.
+	Include []string `json:"include"`
+	// Exclude is the statement types not to record.
+	Exclude []string `json:"exclude"`
+	// SampleRate is the sampling rate between 0 and 1, default is 1.
+	SampleRate *float64 `json:"sample_rate"`
+}
+
+// Record represents an audit record of statement.
+type Record struct {
+	Time         time.Time `json:"time"`
+	ConnectionID uint32    `json:"connection_id"`
+	Tenant       string    `json:"tenant"`
+	User         string    `json:"user"`
+	Schema       string    `json:"schema"`
+	Client       string    `json:"client"`
+	StmtType     string    `json:"stmt_type"`
+	SQL          string    `json:"sql"`
+	Rows         int       `json:"rows"`
+	AffectedRows uint64    `json:"affected_rows"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type factory struct{}
+
+func (f *factory) NewFilter(config json.RawMessage) (proto.Filter, error) {
+	var conf Config
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &conf); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal audit config")
+		}
+	}
+
+	sampleRate := 1.0
+	if conf.SampleRate != nil {
+		sampleRate = *conf.SampleRate
+	}
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, errors.Errorf("invalid audit sample rate %v, it should be between 0 and 1", sampleRate)
+	}
+
+	name := conf.Sink
+	if len(name) < 1 {
+		name = FileSink
+	}
+	newSink, ok := getSink(name)
+	if !ok {
+		return nil, errors.Errorf("no such audit sink '%s'", name)
+	}
+	sink, err := newSink(&conf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create audit sink '%s'", name)
+	}
+
+	return &auditFilter{
+		sink:       sink,
+		include:    toSet(conf.Include),
+		exclude:    toSet(conf.Exclude),
+		sampleRate: sampleRate,
+	}, nil
+}
+
+type auditFilter struct {
+	sink       Sink
+	include    map[string]struct{}
+	exclude    map[string]struct{}
+	sampleRate float64
+}
+
+func (a *auditFilter) GetName() string {
+	return Name
+}
+
+func (a *auditFilter) PostHandle(ctx *proto.Context, result proto.Result, err error) {
+	var stmtType string
+	if ctx.Stmt != nil {
+		stmtType = metrics.StmtType(ctx.Stmt.StmtNode)
+	} else {
+		stmtType = metrics.StmtType(nil)
+	}
+
+	if !a.accept(stmtType) {
+		return
+	}
+
+	record := &Record{
+		Time:         time.Now(),
+		ConnectionID: ctx.ConnectionID,
+		Tenant:       ctx.Tenant,
+		User:         ctx.Username,
+		Schema:       ctx.Schema,
+		Client:       ctx.RemoteAddr,
+		StmtType:     stmtType,
+		SQL:          ctx.GetQuery(),
+	}
+	if result != nil {
+		record.Rows = len(result.GetRows())
+		record.AffectedRows, _ = result.RowsAffected()
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if err := a.sink.Write(record); err != nil {
+		log.Errorf("failed to write audit record: %v", err)
+	}
+}
+
+func (a *auditFilter) accept(stmtType string) bool {
+	if _, ok := a.exclude[stmtType]; ok {
+		return false
+	}
+	if len(a.include) > 0 {
+		if _, ok := a.include[stmtType]; !ok {
+			return false
+		}
+	}
+	return a.sampleRate >= 1 || rand2.Float64() < a.sampleRate
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, it := range values {
+		set[strings.ToLower(strings.TrimSpace(it))] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	filter "github.com/arana-db/arana/pkg/filters"
+	"github.com/arana-db/arana/pkg/mysql"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+type memorySink []*Record
+
+func (m *memorySink) Write(record *Record) error {
+	*m = append(*m, record)
+	return nil
+}
+
+func newContext(t *testing.T, sql string) *proto.Context {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	assert.NoError(t, err)
+	return &proto.Context{
+		Context:      context.Background(),
+		Schema:       "employees",
+		Tenant:       "arana",
+		Username:     "dksl",
+		RemoteAddr:   "127.0.0.1:50000",
+		ConnectionID: 1,
+		Stmt:         &proto.Stmt{StmtNode: stmt},
+	}
+}
+
+func newFilter(t *testing.T, config string) proto.PostFilter {
+	f, err := filter.GetFilterFactory(Name).NewFilter(json.RawMessage(config))
+	assert.NoError(t, err)
+	return f.(proto.PostFilter)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f := newFilter(t, fmt.Sprintf(`{"path":%q}`, path))
+	assert.Equal(t, Name, f.GetName())
+
+	f.PostHandle(newContext(t, "select * from student where uid = 1"), &mysql.Result{Rows: make([]proto.Row, 2)}, nil)
+	f.PostHandle(newContext(t, "delete from student where uid = 1"), &mysql.Result{AffectedRows: 3}, nil)
+	f.PostHandle(newContext(t, "update student set name = 'foo'"), nil, errors.New("oops"))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 3)
+
+	var records []*Record
+	for _, it := range lines {
+		var record Record
+		assert.NoError(t, json.Unmarshal([]byte(it), &record))
+		records = append(records, &record)
+	}
+
+	assert.Equal(t, "select", records[0].StmtType)
+	assert.Equal(t, "arana", records[0].Tenant)
+	assert.Equal(t, "dksl", records[0].User)
+	assert.Equal(t, "employees", records[0].Schema)
+	assert.Equal(t, "127.0.0.1:50000", records[0].Client)
+	assert.Equal(t, uint32(1), records[0].ConnectionID)
+	assert.Equal(t, "select * from student where uid = 1", records[0].SQL)
+	assert.Equal(t, 2, records[0].Rows)
+	assert.Equal(t, "delete", records[1].StmtType)
+	assert.Equal(t, uint64(3), records[1].AffectedRows)
+	assert.Equal(t, "update", records[2].StmtType)
+	assert.Equal(t, "oops", records[2].Error)
+}
+
+func TestFilter(t *testing.T) {
+	var sink memorySink
+	RegisterSink("memory", func(*Config) (Sink, error) {
+		return &sink, nil
+	})
+
+	f := newFilter(t, `{"sink":"memory","include":["select","Insert"],"exclude":["insert"]}`)
+	f.PostHandle(newContext(t, "select 1"), nil, nil)
+	f.PostHandle(newContext(t, "insert into student(uid) values(1)"), nil, nil)
+	f.PostHandle(newContext(t, "delete from student"), nil, nil)
+	assert.Len(t, sink, 1)
+	assert.Equal(t, "select", sink[0].StmtType)
+
+	sink = sink[:0]
+	f = newFilter(t, `{"sink":"memory","sample_rate":0}`)
+	for i := 0; i < 10; i++ {
+		f.PostHandle(newContext(t, "select 1"), nil, nil)
+	}
+	assert.Len(t, sink, 0)
+
+	for _, it := range []string{
+		`{"sink":"memory","sample_rate":1.5}`,
+		`{"sink":"unknown"}`,
+		`{"path":""}`,
+	} {
+		_, err := filter.GetFilterFactory(Name).NewFilter(json.RawMessage(it))
+		assert.Error(t, err, it)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+import (
+	"github.com/natefinch/lumberjack"
+
+	"github.com/pkg/errors"
+)
+
+// FileSink is the name of built-in sink, which writes records into a rotated file as JSON lines.
+const FileSink = "file"
+
+// Sink writes the audit records.
+type Sink interface {
+	Write(record *Record) error
+}
+
+// SinkFactory creates a Sink with the config of audit filter.
+type SinkFactory func(conf *Config) (Sink, error)
+
+var (
+	_sinksLock sync.RWMutex
+	_sinks     = map[string]SinkFactory{
+		FileSink: newFileSink,
+	}
+)
+
+// RegisterSink registers a sink factory, which can be selected by the sink name in config.
+func RegisterSink(name string, factory SinkFactory) {
+	_sinksLock.Lock()
+	defer _sinksLock.Unlock()
+	_sinks[name] = factory
+}
+
+func getSink(name string) (SinkFactory, bool) {
+	_sinksLock.RLock()
+	defer _sinksLock.RUnlock()
+	factory, ok := _sinks[name]
+	return factory, ok
+}
+
+type fileSink struct {
+	mu sync.Mutex
+	w  *lumberjack.Logger
+}
+
+func newFileSink(conf *Config) (Sink, error) {
+	if len(conf.Path) < 1 {
+		return nil, errors.New("no path specified for audit file sink")
+	}
+
+	w := &lumberjack.Logger{
+		Filename:   conf.Path,
+		MaxSize:    conf.MaxSize,
+		MaxBackups: conf.MaxBackups,
+		MaxAge:     conf.MaxAge,
+	}
+	if w.MaxSize < 1 {
+		w.MaxSize = 100
+	}
+	if w.MaxBackups < 1 {
+		w.MaxBackups = 10
+	}
+	if w.MaxAge < 1 {
+		w.MaxAge = 30
+	}
+	return &fileSink{w: w}, nil
+}
+
+func (f *fileSink) Write(record *Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b = append(b, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.w.Write(b)
+	return errors.WithStack(err)
+}
@@ -23,6 +23,8 @@ import (
 )
 
 import (
+	"github.com/arana-db/parser"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,3 +68,36 @@ type PreFilterTest struct {
 func (filter *PreFilterTest) GetName() string {
 	return "PreFilterTest"
 }
+
+func TestRewrite(t *testing.T) {
+	ctx := &proto.Context{}
+	assert.NoError(t, Rewrite(ctx, "select * from student limit 10"))
+	assert.Equal(t, "select * from student limit 10", ctx.GetQuery())
+
+	ctx.Stmt.PrepareStmt = "select * from student where uid = ?"
+	assert.NoError(t, Rewrite(ctx, "select * from student where uid = ? limit 10"))
+	assert.Equal(t, "select * from student where uid = ? limit 10", ctx.GetQuery())
+
+	assert.Error(t, Rewrite(ctx, "select * fro"))
+	assert.Equal(t, "select * from student where uid = ? limit 10", ctx.GetQuery())
+}
+
+func TestRewritePreparedStmt(t *testing.T) {
+	const query = "select * from student where uid = ?"
+	stmt, err := parser.New().ParseOneStmt(query, "", "")
+	assert.NoError(t, err)
+	prepared := &proto.Stmt{StatementID: 1, PrepareStmt: query, StmtNode: stmt}
+
+	// execute the same prepared statement twice, each execution appends a limit
+	for i := 0; i < 2; i++ {
+		ctx := &proto.Context{Stmt: prepared}
+		assert.NoError(t, Rewrite(ctx, ctx.GetQuery()+" limit 10"))
+		assert.Equal(t, query+" limit 10", ctx.GetQuery())
+		assert.Equal(t, query+" limit 10", ctx.Stmt.StmtNode.Text())
+		assert.Equal(t, uint32(1), ctx.Stmt.StatementID)
+	}
+
+	// the prepared statement is not changed
+	assert.Equal(t, query, prepared.PrepareStmt)
+	assert.Equal(t, query, prepared.StmtNode.Text())
+}
@@ -0,0 +1,42 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package firewall
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	_commentRegexp = regexp.MustCompile(`(?s)/\*.*?\*/|(--|#)[^\n]*`)
+	_stringRegexp  = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+	_numberRegexp  = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:e[+-]?\d+)?\b`)
+	_spaceRegexp   = regexp.MustCompile(`\s+`)
+)
+
+// fingerprint normalizes the sql by removing comments, replacing literals with '?' and collapsing whitespaces, eg:
+//
+//	SELECT * FROM student WHERE uid = 1 AND name = 'foo' -> select * from student where uid = ? and name = ?
+func fingerprint(sql string) string {
+	sql = _commentRegexp.ReplaceAllString(sql, " ")
+	sql = _stringRegexp.ReplaceAllString(sql, "?")
+	sql = strings.ToLower(sql)
+	sql = _numberRegexp.ReplaceAllString(sql, "?")
+	sql = _spaceRegexp.ReplaceAllString(sql, " ")
+	return strings.TrimSpace(sql)
+}
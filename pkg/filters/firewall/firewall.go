@@ -0,0 +1,216 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package firewall provides the firewall filter, which denies the statements matched by any rule.
+package firewall
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/parser/ast"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	filter "github.com/arana-db/arana/pkg/filters"
+	"github.com/arana-db/arana/pkg/metrics"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// Name is the name of firewall filter.
+const Name = "firewall"
+
+var (
+	_ proto.FilterFactory = (*factory)(nil)
+	_ proto.PreFilter     = (*firewallFilter)(nil)
+)
+
+func init() {
+	filter.RegistryFilterFactory(Name, &factory{})
+}
+
+// Config represents the config of firewall filter.
+type Config struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule represents a deny rule, a statement is denied if it matches all the conditions of rule.
+type Rule struct {
+	// Name is the name of rule, which will be sent to client when a statement is denied.
+	Name string `json:"name"`
+	// StmtTypes is the statement types to deny, eg: delete, droptable, all types will be matched if empty.
+	StmtTypes []string `json:"stmt_types"`
+	// NoWhere denies the UPDATE/DELETE statements without WHERE clause.
+	NoWhere bool `json:"no_where"`
+	// Tables is the tables to deny, eg: student, employees.student.
+	Tables []string `json:"tables"`
+	// Pattern is the regular expression to match the fingerprint of statement, eg: select * from student where uid = ?
+	Pattern string `json:"pattern"`
+}
+
+type factory struct{}
+
+func (f *factory) NewFilter(config json.RawMessage) (proto.Filter, error) {
+	var conf Config
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &conf); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal firewall config")
+		}
+	}
+
+	rules := make([]*rule, 0, len(conf.Rules))
+	for i, it := range conf.Rules {
+		r, err := newRule(it)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid firewall rule #%d", i)
+		}
+		if len(r.name) < 1 {
+			r.name = fmt.Sprintf("#%d", i)
+		}
+		rules = append(rules, r)
+	}
+
+	return &firewallFilter{rules: rules}, nil
+}
+
+type rule struct {
+	name      string
+	stmtTypes map[string]struct{}
+	noWhere   bool
+	tables    map[string]struct{}
+	pattern   *regexp.Regexp
+}
+
+func newRule(conf *Rule) (*rule, error) {
+	r := &rule{
+		name:      conf.Name,
+		stmtTypes: toSet(conf.StmtTypes),
+		noWhere:   conf.NoWhere,
+		tables:    toSet(conf.Tables),
+	}
+	if len(conf.Pattern) > 0 {
+		pattern, err := regexp.Compile(conf.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile pattern '%s'", conf.Pattern)
+		}
+		r.pattern = pattern
+	}
+	if len(r.stmtTypes) < 1 && !r.noWhere && len(r.tables) < 1 && r.pattern == nil {
+		return nil, errors.New("no condition specified")
+	}
+	return r, nil
+}
+
+func (r *rule) match(ctx *proto.Context, stmt ast.StmtNode) bool {
+	if len(r.stmtTypes) > 0 {
+		if _, ok := r.stmtTypes[metrics.StmtType(stmt)]; !ok {
+			return false
+		}
+	}
+	if r.noWhere && !isNoWhere(stmt) {
+		return false
+	}
+	if len(r.tables) > 0 && !r.matchTables(ctx.Schema, stmt) {
+		return false
+	}
+	if r.pattern != nil && !r.pattern.MatchString(fingerprint(ctx.GetQuery())) {
+		return false
+	}
+	return true
+}
+
+func (r *rule) matchTables(schema string, stmt ast.StmtNode) bool {
+	var v tableVisitor
+	stmt.Accept(&v)
+	for _, it := range v.tables {
+		db := it.Schema.L
+		if len(db) < 1 {
+			db = strings.ToLower(schema)
+		}
+		if _, ok := r.tables[it.Name.L]; ok {
+			return true
+		}
+		if _, ok := r.tables[db+"."+it.Name.L]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type firewallFilter struct {
+	rules []*rule
+}
+
+func (f *firewallFilter) GetName() string {
+	return Name
+}
+
+func (f *firewallFilter) PreHandle(ctx *proto.Context) (proto.Result, error) {
+	if ctx.Stmt == nil || ctx.Stmt.StmtNode == nil {
+		return nil, nil
+	}
+	for _, it := range f.rules {
+		if it.match(ctx, ctx.Stmt.StmtNode) {
+			return nil, err2.NewSQLError(mysql.ERSpecifiedAccessDenied, mysql.SSSyntaxErrorOrAccessViolation,
+				"statement denied by firewall rule '%s'", it.name)
+		}
+	}
+	return nil, nil
+}
+
+// isNoWhere returns true if the statement is an UPDATE/DELETE without WHERE clause.
+func isNoWhere(stmt ast.StmtNode) bool {
+	switch s := stmt.(type) {
+	case *ast.UpdateStmt:
+		return s.Where == nil
+	case *ast.DeleteStmt:
+		return s.Where == nil
+	default:
+		return false
+	}
+}
+
+type tableVisitor struct {
+	tables []*ast.TableName
+}
+
+func (v *tableVisitor) Enter(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok {
+		v.tables = append(v.tables, t)
+	}
+	return n, false
+}
+
+func (v *tableVisitor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, it := range values {
+		set[strings.ToLower(strings.TrimSpace(it))] = struct{}{}
+	}
+	return set
+}
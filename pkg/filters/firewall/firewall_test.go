@@ -0,0 +1,108 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package firewall
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+import (
+	"github.com/arana-db/parser"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	filter "github.com/arana-db/arana/pkg/filters"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+func newContext(t *testing.T, sql string) *proto.Context {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	assert.NoError(t, err)
+	return &proto.Context{
+		Context: context.Background(),
+		Schema:  "employees",
+		Stmt:    &proto.Stmt{StmtNode: stmt},
+	}
+}
+
+func TestFirewall(t *testing.T) {
+	f, err := filter.GetFilterFactory(Name).NewFilter(json.RawMessage(`{
+		"rules": [
+			{"name": "no-where", "no_where": true},
+			{"name": "drop", "stmt_types": ["droptable", "truncatetable"]},
+			{"name": "salary", "stmt_types": ["delete"], "tables": ["employees.salaries"]},
+			{"tables": ["secrets"]},
+			{"name": "sleep", "pattern": "sleep\\(\\?\\)"}
+		]
+	}`))
+	assert.NoError(t, err)
+	pre := f.(proto.PreFilter)
+	assert.Equal(t, Name, pre.GetName())
+
+	for sql, rule := range map[string]string{
+		"update student set name = 'foo'":                     "no-where",
+		"delete from student":                                 "no-where",
+		"drop table student":                                  "drop",
+		"truncate table student":                              "drop",
+		"delete from salaries where uid = 1":                  "salary",
+		"delete from employees.salaries where uid = 1":        "salary",
+		"select * from student a join secrets b on a.id=b.id": "#3",
+		"select * from other.secrets":                         "#3",
+		"select SLEEP(10) from student where uid = 1":         "sleep",
+	} {
+		res, err := pre.PreHandle(newContext(t, sql))
+		assert.Nil(t, res)
+		se, ok := err.(*err2.SQLError)
+		if assert.True(t, ok, sql) {
+			assert.Equal(t, mysql.ERSpecifiedAccessDenied, se.Number(), sql)
+			assert.Contains(t, se.Message, "'"+rule+"'", sql)
+		}
+	}
+
+	for _, sql := range []string{
+		"update student set name = 'foo' where uid = 1",
+		"delete from student where uid = 1",
+		"select * from salaries",
+		"delete from other.salaries where uid = 1",
+		"select * from student where name = 'sleep(1)'",
+	} {
+		res, err := pre.PreHandle(newContext(t, sql))
+		assert.Nil(t, res)
+		assert.NoError(t, err, sql)
+	}
+
+	for _, it := range []string{
+		`{"rules":[{"name":"empty"}]}`,
+		`{"rules":[{"pattern":"("}]}`,
+	} {
+		_, err := filter.GetFilterFactory(Name).NewFilter(json.RawMessage(it))
+		assert.Error(t, err, it)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t,
+		"select * from t1 where uid = ? and name = ? and score > ?",
+		fingerprint("SELECT *  FROM t1 /* hint */ WHERE uid = 1\n AND name = 'it''s' AND score > 1.5 -- comment"))
+}
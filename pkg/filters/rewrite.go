@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filter
+
+import (
+	"github.com/arana-db/parser"
+	_ "github.com/arana-db/parser/test_driver"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// Rewrite replaces the statement of context with the given sql, it should be called in PreHandle.
+// The placeholders of a prepared statement must be kept as is.
+// The statement is copied before changed, since a prepared statement is shared by all its executions.
+func Rewrite(ctx *proto.Context, sql string) error {
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse rewritten sql '%s'", sql)
+	}
+
+	var next proto.Stmt
+	if ctx.Stmt != nil {
+		next = *ctx.Stmt
+	}
+	if len(next.PrepareStmt) > 0 {
+		next.PrepareStmt = sql
+	}
+	next.StmtNode = stmt
+	ctx.Stmt = &next
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package metrics defines the prometheus metrics of arana.
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const _namespace = "arana"
+
+const (
+	TxCommit   = "commit"
+	TxRollback = "rollback"
+)
+
+// PlanDirect is the plan type of queries which are executed directly without optimizing.
+const PlanDirect = "direct"
+
+var (
+	// FrontendConnections is the amount of frontend connections per listener and tenant.
+	FrontendConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: _namespace,
+		Subsystem: "frontend",
+		Name:      "connections",
+		Help:      "Number of frontend connections.",
+	}, []string{"listener", "tenant"})
+
+	// QueryTotal is the amount of queries by statement type and plan type.
+	QueryTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: "query",
+		Name:      "total",
+		Help:      "Number of executed queries.",
+	}, []string{"stmt_type", "plan_type", "result"})
+
+	// QueryDuration is the latency of queries by statement type and plan type.
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Subsystem: "query",
+		Name:      "duration_seconds",
+		Help:      "Latency of executed queries.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16), // 0.5ms ~ 16s
+	}, []string{"stmt_type", "plan_type"})
+
+	// ShardFanout is the amount of physical tables which a query is routed to.
+	ShardFanout = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: _namespace,
+		Subsystem: "query",
+		Name:      "shard_fanout",
+		Help:      "Number of physical tables a query is routed to.",
+		Buckets:   []float64{0, 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024},
+	})
+
+	// FullScanTotal is the amount of full-scan queries.
+	FullScanTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: "query",
+		Name:      "full_scan_total",
+		Help:      "Number of full-scan queries.",
+	})
+
+	// TxTotal is the amount of finished transactions by action, which is commit or rollback.
+	TxTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _namespace,
+		Subsystem: "tx",
+		Name:      "total",
+		Help:      "Number of finished transactions.",
+	}, []string{"action", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		FrontendConnections,
+		QueryTotal,
+		QueryDuration,
+		ShardFanout,
+		FullScanTotal,
+		TxTotal,
+	)
+}
+
+// ObserveQuery records a finished query.
+func ObserveQuery(stmtType, planType string, start time.Time, err error) {
+	QueryTotal.WithLabelValues(stmtType, planType, resultOf(err)).Inc()
+	QueryDuration.WithLabelValues(stmtType, planType).Observe(time.Since(start).Seconds())
+}
+
+// ObserveTx records a finished transaction.
+func ObserveTx(action string, err error) {
+	TxTotal.WithLabelValues(action, resultOf(err)).Inc()
+}
+
+// ObserveShards records the fan-out width of a query.
+func ObserveShards(fanout int, fullScan bool) {
+	if fullScan {
+		FullScanTotal.Inc()
+	}
+	ShardFanout.Observe(float64(fanout))
+}
+
+// StmtType returns the statement type of given statement node, eg: *ast.SelectStmt -> select.
+func StmtType(stmt interface{}) string {
+	name := typeName(stmt)
+	if len(name) < 1 {
+		return "unknown"
+	}
+	return strings.ToLower(strings.TrimSuffix(name, "Stmt"))
+}
+
+// PlanType returns the plan type of given plan, eg: *plan.UnionPlan -> UnionPlan.
+func PlanType(plan interface{}) string {
+	name := typeName(plan)
+	if len(name) < 1 {
+		return "unknown"
+	}
+	return name
+}
+
+func typeName(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func resultOf(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,58 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/arana-db/parser/ast"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePlan struct{}
+
+func TestTypes(t *testing.T) {
+	assert.Equal(t, "select", StmtType(&ast.SelectStmt{}))
+	assert.Equal(t, "insert", StmtType(&ast.InsertStmt{}))
+	assert.Equal(t, "unknown", StmtType(nil))
+	assert.Equal(t, "fakePlan", PlanType(&fakePlan{}))
+	assert.Equal(t, "fakePlan", PlanType(fakePlan{}))
+	assert.Equal(t, "unknown", PlanType(nil))
+}
+
+func TestObserve(t *testing.T) {
+	ObserveQuery("select", "SimpleQueryPlan", time.Now(), nil)
+	ObserveQuery("select", "SimpleQueryPlan", time.Now(), errors.New("oops"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(QueryTotal.WithLabelValues("select", "SimpleQueryPlan", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(QueryTotal.WithLabelValues("select", "SimpleQueryPlan", "error")))
+
+	before := testutil.ToFloat64(FullScanTotal)
+	ObserveShards(4, true)
+	ObserveShards(1, false)
+	assert.Equal(t, before+1, testutil.ToFloat64(FullScanTotal))
+
+	ObserveTx(TxCommit, nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(TxTotal.WithLabelValues(TxCommit, "ok")))
+}
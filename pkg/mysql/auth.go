@@ -24,8 +24,10 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -209,6 +211,47 @@ func scramblePassword(scramble []byte, password string) []byte {
 	return scramble
 }
 
+// NativePasswordHash returns the mysql_native_password hash of password, which is same as PASSWORD() of MySQL.
+func NativePasswordHash(password string) string {
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	return "*" + strings.ToUpper(hex.EncodeToString(stage2[:]))
+}
+
+// isNativePasswordHash returns true if the password is a mysql_native_password hash, eg: *6BB4837EB74329105EE4568DDA7DC67ED2CA2AD9
+func isNativePasswordHash(password string) bool {
+	if len(password) != 2*sha1.Size+1 || password[0] != '*' {
+		return false
+	}
+	_, err := hex.DecodeString(password[1:])
+	return err == nil
+}
+
+// checkNativePasswordHash checks the auth response against the mysql_native_password hash.
+func checkNativePasswordHash(scramble, authResponse []byte, hash string) bool {
+	if len(authResponse) != sha1.Size {
+		return false
+	}
+	stage2, err := hex.DecodeString(hash[1:])
+	if err != nil {
+		return false
+	}
+
+	// stage1Hash = authResponse XOR SHA1(scramble + stage2Hash)
+	crypt := sha1.New()
+	crypt.Write(scramble)
+	crypt.Write(stage2)
+	stage1 := crypt.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= authResponse[i]
+	}
+
+	// check SHA1(stage1Hash) == stage2Hash
+	crypt.Reset()
+	crypt.Write(stage1)
+	return bytes.Equal(crypt.Sum(nil), stage2)
+}
+
 // Hash password using MySQL 8+ method (SHA256)
 func scrambleSHA256Password(scramble []byte, password string) []byte {
 	if len(password) == 0 {
@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+func TestNativePasswordHash(t *testing.T) {
+	hash := NativePasswordHash("123456")
+	assert.Equal(t, "*6BB4837EB74329105EE4568DDA7DC67ED2CA2AD9", hash)
+	assert.True(t, isNativePasswordHash(hash))
+	assert.False(t, isNativePasswordHash("123456"))
+
+	salt, err := newSalt()
+	assert.NoError(t, err)
+	assert.True(t, checkNativePasswordHash(salt, scramblePassword(salt, "123456"), hash))
+	assert.False(t, checkNativePasswordHash(salt, scramblePassword(salt, "654321"), hash))
+}
+
+func TestValidateHash(t *testing.T) {
+	const (
+		tenant  = "fake_auth_tenant"
+		cluster = "fake_auth_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "plain", Password: "123456"})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "hashed", Password: NativePasswordHash("123456")})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	salt, err := newSalt()
+	assert.NoError(t, err)
+
+	var l Listener
+	for _, username := range []string{"plain", "hashed"} {
+		handshake := &handshakeResult{
+			schema:       cluster,
+			username:     username,
+			salt:         salt,
+			authResponse: scramblePassword(salt, "123456"),
+		}
+		assert.NoError(t, l.ValidateHash(handshake))
+		assert.Equal(t, tenant, handshake.tenant)
+
+		handshake.authResponse = scramblePassword(salt, "654321")
+		assert.Error(t, l.ValidateHash(handshake))
+	}
+}
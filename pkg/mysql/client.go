@@ -482,6 +482,9 @@ type BackendConnection struct {
 	serverVersion string
 
 	characterSet uint8
+
+	// variables is the session variables applied on the connection.
+	variables []proto.Variable
 }
 
 func (conn *BackendConnection) DBName() string {
@@ -501,7 +504,8 @@ func (conn *BackendConnection) Connect(ctx context.Context) error {
 	} else {
 		typ = conn.conf.Net
 	}
-	netConn, err := net.Dial(typ, conn.conf.Addr)
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, typ, conn.conf.Addr)
 	if err != nil {
 		return err
 	}
@@ -518,7 +522,15 @@ func (conn *BackendConnection) Connect(ctx context.Context) error {
 	}
 
 	conn.c = newConn(tcpConn)
+	conn.variables = nil
 
+	// the handshake should be finished before the deadline too
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = tcpConn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer tcpConn.SetDeadline(time.Time{})
+	}
 	return conn.clientHandshake()
 }
 
@@ -1381,3 +1393,22 @@ func (conn *BackendConnection) prepare(query string) (*BackendStatement, error)
 func (conn *BackendConnection) Close() {
 	conn.c.Close()
 }
+
+// KillQuery kills the running query of connection, it's sent through a new connection since the current one is busy.
+func (conn *BackendConnection) KillQuery(ctx context.Context) error {
+	killer := &BackendConnection{conf: conn.conf}
+	err := killer.Connect(ctx)
+	if killer.c != nil {
+		defer killer.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = killer.c.conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	_, err = killer.Execute(fmt.Sprintf("KILL QUERY %d", conn.c.ConnectionID), false)
+	return err
+}
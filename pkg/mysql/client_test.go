@@ -18,7 +18,11 @@
 package mysql
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 )
 
 import (
@@ -409,3 +413,30 @@ func TestReadColumnDefinitionType(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, mysql.FieldTypeVarChar, field.fieldType)
 }
+
+func TestKillQueryTimeout(t *testing.T) {
+	// the server accepts connections, but never sends the handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg, err := ParseDSN(fmt.Sprintf("foo:123456@tcp(%s)/db", l.Addr()))
+	assert.NoError(t, err)
+	bc := &BackendConnection{conf: cfg}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Error(t, bc.KillQuery(ctx))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -39,6 +39,7 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/constants/mysql"
 	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/third_party/bucketpool"
 )
 
@@ -88,6 +89,9 @@ type Conn struct {
 	// Tenant is the current tenant login.
 	Tenant string
 
+	// Username is the current user login.
+	Username string
+
 	// ConnectionID is set:
 	// - at Connect() time for clients, with the value returned by
 	// the server.
@@ -122,6 +126,34 @@ type Conn struct {
 	// It is only used by the server. These flags can be changed
 	// by Handler methods.
 	StatusFlags uint16
+
+	// session is the state of frontend session, it is only used by the server.
+	session *proto.Session
+
+	// process tracks the running statement, it is only used by the server.
+	process *proto.Process
+
+	// busy is true if the connection is executing a command, it is guarded by Listener.mu.
+	busy bool
+
+	// salt is kept from handshake, which is used by COM_CHANGE_USER.
+	salt []byte
+	// clientFlags is the capabilities negotiated by handshake,
+	// CLIENT_MULTI_STATEMENTS of it could be changed by COM_SET_OPTION.
+	clientFlags uint32
+
+	// stmts is the ids of prepared statements of connection, it is only used by the server.
+	stmts map[uint32]struct{}
+
+	// cursors is the open cursors of prepared statements, it is only used by the server.
+	cursors map[uint32]*cursor
+
+	// longData is the parameters of prepared statements sent by COM_STMT_SEND_LONG_DATA,
+	// key is the index of parameter, it is only used by the server.
+	longData map[uint32]map[uint16][]byte
+
+	// release releases the quota of connection, it is only used by the server.
+	release func()
 }
 
 // newConn is an internal method to create a Conn. Used by client and server
@@ -648,7 +680,8 @@ func (c *Conn) writeErrorPacket(errorCode uint16, sqlState string, format string
 // writeErrorPacketFromError writes an error packet, from a regular error.
 // See writeErrorPacket for other info.
 func (c *Conn) writeErrorPacketFromError(err error) error {
-	if se, ok := err.(*err2.SQLError); ok {
+	var se *err2.SQLError
+	if errors.As(err, &se) {
 		return c.writeErrorPacket(uint16(se.Num), se.State, "%v", se.Message)
 	}
 
@@ -0,0 +1,53 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// cursor is a proxy-side cursor opened by COM_STMT_EXECUTE with CURSOR_TYPE_READ_ONLY,
+// the rows of result are fetched by COM_STMT_FETCH.
+//
+// The executor returns the loaded result, so the cursor pages the rows of it to the client,
+// and every row is released once sent, which lets the memory be reclaimed while fetching.
+type cursor struct {
+	rows []proto.Row
+	pos  int
+}
+
+// newCursor opens a cursor over the rows of result.
+func newCursor(result proto.Result) *cursor {
+	return &cursor{rows: result.GetRows()}
+}
+
+// fetch returns at most n rows, last is true if all rows have been fetched.
+func (cur *cursor) fetch(n uint32) (rows []proto.Row, last bool) {
+	end := len(cur.rows)
+	if remain := uint64(end - cur.pos); uint64(n) < remain {
+		end = cur.pos + int(n)
+	}
+	rows = make([]proto.Row, end-cur.pos)
+	copy(rows, cur.rows[cur.pos:end])
+	// the fetched rows are not referenced by cursor any more
+	for i := cur.pos; i < end; i++ {
+		cur.rows[i] = nil
+	}
+	cur.pos = end
+	return rows, cur.pos >= len(cur.rows)
+}
@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+func TestCursorFetch(t *testing.T) {
+	result := &Result{Rows: []proto.Row{&Row{}, &Row{}, &Row{}}}
+	cur := newCursor(result)
+
+	rows, last := cur.fetch(2)
+	assert.Equal(t, []proto.Row{&Row{}, &Row{}}, rows)
+	assert.False(t, last)
+	// the fetched rows are released
+	assert.Equal(t, []proto.Row{nil, nil, &Row{}}, result.Rows)
+
+	rows, last = cur.fetch(2)
+	assert.Len(t, rows, 1)
+	assert.True(t, last)
+
+	rows, last = cur.fetch(0)
+	assert.Len(t, rows, 0)
+	assert.True(t, last)
+}
+
+func TestStmtFetch(t *testing.T) {
+	const (
+		tenant  = "fake_cursor_tenant"
+		cluster = "fake_cursor_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	l.SetExecutor(&fakeQueryExecutor{})
+	go l.Listen()
+	defer l.Close()
+
+	connector, err := NewConnector([]byte(fmt.Sprintf(`{"dsn": "foo:123456@tcp(%s)/%s"}`, l.listener.Addr(), cluster)))
+	assert.NoError(t, err)
+	res, err := connector.NewBackendConnection(context.Background())
+	assert.NoError(t, err)
+	bc := res.(*BackendConnection)
+	defer bc.Close()
+
+	stmt, err := bc.prepare("select a from t")
+	assert.NoError(t, err)
+
+	// writeCommand writes a command of statement, and returns the packets of response until the end or an error
+	writeCommand := func(command byte, payload ...byte) ([][]byte, error) {
+		data := make([]byte, 5, 5+len(payload))
+		data[0] = command
+		binary.LittleEndian.PutUint32(data[1:], stmt.id)
+		bc.c.sequence = 0
+		if err := bc.c.writePacket(append(data, payload...)); err != nil {
+			return nil, err
+		}
+		var packets [][]byte
+		for {
+			packet, err := bc.c.readPacket()
+			if err != nil {
+				return nil, err
+			}
+			switch packet[0] {
+			case consts.ErrPacket:
+				return nil, ParseErrorPacket(packet)
+			case consts.OKPacket, consts.EOFPacket:
+				// the binary rows fetched are started with 0x00 too
+				if command != consts.ComStmtFetch || packet[0] == consts.EOFPacket {
+					return append(packets, packet), nil
+				}
+			}
+			packets = append(packets, packet)
+		}
+	}
+	// statusOf returns the status flags of the OK packet with EOF header
+	statusOf := func(packet []byte) uint16 {
+		return binary.LittleEndian.Uint16(packet[3:])
+	}
+	execute := func() {
+		// cursor type, iteration count
+		packets, err := writeCommand(consts.ComStmtExecute, consts.CursorTypeReadOnly, 1, 0, 0, 0)
+		if assert.NoError(t, err) && assert.Len(t, packets, 3) {
+			// column count, column definition and the end of fields
+			assert.NotZero(t, statusOf(packets[2])&consts.ServerStatusCursorExists)
+		}
+	}
+	fetch := func(n byte) ([][]byte, error) {
+		return writeCommand(consts.ComStmtFetch, n, 0, 0, 0)
+	}
+	assertNoCursor := func(err error) {
+		if assert.IsType(t, (*errors.SQLError)(nil), err) {
+			assert.Equal(t, consts.ERStmtHasNoOpenCursor, err.(*errors.SQLError).Num)
+		}
+	}
+
+	execute()
+	packets, err := fetch(2)
+	if assert.NoError(t, err) && assert.Len(t, packets, 3) {
+		assert.Equal(t, []byte{0x00, 0x00, 1, '0'}, packets[0])
+		assert.Equal(t, []byte{0x00, 0x00, 1, '1'}, packets[1])
+		assert.Zero(t, statusOf(packets[2])&consts.ServerStatusLastRowSent)
+	}
+	packets, err = fetch(2)
+	if assert.NoError(t, err) && assert.Len(t, packets, 2) {
+		assert.Equal(t, []byte{0x00, 0x00, 1, '2'}, packets[0])
+		assert.NotZero(t, statusOf(packets[1])&consts.ServerStatusLastRowSent)
+	}
+
+	// the cursor is closed after the last row is sent
+	_, err = fetch(2)
+	assertNoCursor(err)
+
+	// the cursor is closed by COM_STMT_RESET
+	execute()
+	_, err = writeCommand(consts.ComStmtReset)
+	assert.NoError(t, err)
+	_, err = fetch(2)
+	assertNoCursor(err)
+}
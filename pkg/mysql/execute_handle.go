@@ -18,11 +18,14 @@
 package mysql
 
 import (
+	"crypto/tls"
+	"net"
 	"strings"
 )
 
 import (
 	"github.com/arana-db/parser"
+	"github.com/arana-db/parser/ast"
 )
 
 import (
@@ -30,6 +33,7 @@ import (
 	"github.com/arana-db/arana/pkg/mysql/errors"
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -37,6 +41,24 @@ func (l *Listener) handleInitDB(c *Conn, ctx *proto.Context) error {
 	db := string(ctx.Data[1:])
 	c.recycleReadPacket()
 
+	if err := l.useDB(c, ctx, db); err != nil {
+		if wErr := c.writeErrorPacketFromError(err); wErr != nil {
+			log.Errorf("failed to write ComInitDB error to %s: %v", c, wErr)
+			return wErr
+		}
+		return nil
+	}
+
+	if err := c.writeOKPacket(0, 0, c.StatusFlags, 0); err != nil {
+		log.Errorf("Error writing ComInitDB result to %s: %v", c, err)
+		return err
+	}
+
+	return nil
+}
+
+// useDB switches the schema of connection, which is used by COM_INIT_DB and USE statement.
+func (l *Listener) useDB(c *Conn, ctx *proto.Context, db string) error {
 	var allow bool
 	for _, it := range security.DefaultTenantManager().GetClusters(c.Tenant) {
 		if db == it {
@@ -46,26 +68,29 @@ func (l *Listener) handleInitDB(c *Conn, ctx *proto.Context) error {
 	}
 
 	if !allow {
-		if err := c.writeErrorPacketFromError(errors.NewSQLError(mysql.ERBadDb, "", "Unknown database '%s'", db)); err != nil {
-			log.Errorf("failed to write ComInitDB error to %s: %v", c, err)
-			return err
-		}
-		return nil
+		return errors.NewSQLError(mysql.ERBadDb, "", "Unknown database '%s'", db)
 	}
 
-	c.Schema = db
-	err := l.executor.ExecuteUseDB(ctx)
-	if err != nil {
+	ctx.Schema = db
+	if err := l.executor.ExecuteUseDB(ctx); err != nil {
 		return err
 	}
-	if err = c.writeOKPacket(0, 0, c.StatusFlags, 0); err != nil {
-		log.Errorf("Error writing ComInitDB result to %s: %v", c, err)
-		return err
-	}
-
+	c.Schema = db
+	c.process.SetSchema(db)
 	return nil
 }
 
+// updateStatusFlags updates the status flags by the state of session.
+func (l *Listener) updateStatusFlags(c *Conn, ctx *proto.Context) {
+	c.StatusFlags &^= mysql.ServerStatusInTrans | mysql.ServerStatusAutocommit
+	if c.session.IsAutocommit() {
+		c.StatusFlags |= mysql.ServerStatusAutocommit
+	}
+	if l.executor.InLocalTransaction(ctx) {
+		c.StatusFlags |= mysql.ServerStatusInTrans
+	}
+}
+
 func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 	c.startWriterBuffering()
 	defer func() {
@@ -75,7 +100,8 @@ func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 	}()
 
 	c.recycleReadPacket()
-	result, warn, err := l.executor.ExecutorComQuery(ctx)
+
+	stmts, err := l.parseQuery(c, ctx)
 	if err != nil {
 		if wErr := c.writeErrorPacketFromError(err); wErr != nil {
 			log.Error("Error writing query error to client %v: %v", l.connectionID, wErr)
@@ -83,6 +109,86 @@ func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 		}
 		return nil
 	}
+
+	// execute the statements in order, and stop on the first error
+	base := ctx.Context
+	for i, stmt := range stmts {
+		ctx.Context = c.process.Begin(base, proto.CommandQuery, strings.Trim(stmt.Text(), _stmtCutset))
+		ctx.Stmt = &proto.Stmt{StmtNode: stmt}
+
+		var (
+			result proto.Result
+			warn   uint16
+		)
+		switch it := stmt.(type) {
+		case *ast.UseStmt:
+			result, err = &Result{}, l.useDB(c, ctx, it.DBName)
+		case *ast.KillStmt:
+			result, err = &Result{}, l.kill(c, it)
+		case *ast.ShowStmt:
+			if it.Tp == ast.ShowProcessList {
+				result, err = l.showProcessList(c, it.Full), nil
+				break
+			}
+			result, warn, err = l.executor.ExecutorComQuery(ctx)
+		default:
+			result, warn, err = l.executor.ExecutorComQuery(ctx)
+		}
+		if c.process.End() && err != nil {
+			err = errQueryInterrupted()
+		}
+		l.updateStatusFlags(c, ctx)
+		if err != nil {
+			if wErr := c.writeErrorPacketFromError(err); wErr != nil {
+				log.Error("Error writing query error to client %v: %v", l.connectionID, wErr)
+				return wErr
+			}
+			return nil
+		}
+		if err = l.writeQueryResult(c, result, warn, i < len(stmts)-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseQuery parses the statements of COM_QUERY, multiple statements are allowed only if the client enables it.
+func (l *Listener) parseQuery(c *Conn, ctx *proto.Context) ([]ast.StmtNode, error) {
+	query := string(ctx.Data[1:])
+
+	_, span := trace.Start(ctx.Context, "parse")
+	stmts, _, err := parser.New().Parse(query, "", "")
+	trace.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("ComQuery: %s", query)
+
+	switch {
+	case len(stmts) == 0:
+		return nil, errors.NewSQLError(mysql.EREmptyQuery, mysql.SSSyntaxErrorOrAccessViolation, "Query was empty")
+	case len(stmts) == 1:
+		return stmts, nil
+	case c.clientFlags&mysql.CapabilityClientMultiStatements == 0:
+		return nil, errors.NewSQLError(mysql.ERParseError, mysql.SSSyntaxErrorOrAccessViolation,
+			"You have an error in your SQL syntax; multiple statements are not enabled by client")
+	}
+
+	// strip the delimiters and blanks around each statement
+	for _, stmt := range stmts {
+		stmt.SetText(nil, strings.Trim(stmt.Text(), _stmtCutset))
+	}
+	return stmts, nil
+}
+
+const _stmtCutset = "; \t\r\n"
+
+// writeQueryResult writes the result of COM_QUERY, more should be true if there are more results to be sent.
+func (l *Listener) writeQueryResult(c *Conn, result proto.Result, warn uint16, more bool) error {
+	flags := c.StatusFlags
+	if more {
+		flags |= mysql.ServerMoreResultsExists
+	}
 	if len(result.GetFields()) == 0 {
 		// A successful callback with no fields means that this was a
 		// DML or other write-only operation.
@@ -94,15 +200,15 @@ func (l *Listener) handleQuery(c *Conn, ctx *proto.Context) error {
 			affected, _ = result.RowsAffected()
 			insertId, _ = result.LastInsertId()
 		)
-		return c.writeOKPacket(affected, insertId, c.StatusFlags, warn)
+		return c.writeOKPacket(affected, insertId, flags, warn)
 	}
-	if err = c.writeFields(l.capabilities, result); err != nil {
+	if err := c.writeFields(l.capabilities, result); err != nil {
 		return err
 	}
-	if err = c.writeRows(result); err != nil {
+	if err := c.writeRows(result); err != nil {
 		return err
 	}
-	if err = c.writeEndResult(l.capabilities, false, 0, 0, warn); err != nil {
+	if err := c.writeEndResult(l.capabilities, more, 0, 0, warn); err != nil {
 		log.Errorf("Error writing result to %s: %v", c, err)
 		return err
 	}
@@ -130,9 +236,12 @@ func (l *Listener) handleStmtExecute(c *Conn, ctx *proto.Context) error {
 			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
 		}
 	}()
-	stmtID, _, err := c.parseComStmtExecute(&l.stmts, ctx.Data)
+	stmtID, cursorType, err := c.parseComStmtExecute(&l.stmts, ctx.Data)
 	c.recycleReadPacket()
 
+	// the cursor opened by the previous execution is closed
+	delete(c.cursors, stmtID)
+
 	if stmtID != uint32(0) {
 		defer func() {
 			// Allocate a new bindvar map every time since VTGate.Execute() mutates it.
@@ -140,6 +249,7 @@ func (l *Listener) handleStmtExecute(c *Conn, ctx *proto.Context) error {
 				prepareStmt, _ := prepare.(*proto.Stmt)
 				prepareStmt.BindVars = make(map[string]interface{}, prepareStmt.ParamsCount)
 			}
+			delete(c.longData, stmtID)
 		}()
 	}
 
@@ -155,7 +265,12 @@ func (l *Listener) handleStmtExecute(c *Conn, ctx *proto.Context) error {
 	prepareStmt, _ := l.stmts.Load(stmtID)
 	ctx.Stmt = prepareStmt.(*proto.Stmt)
 
+	ctx.Context = c.process.Begin(ctx.Context, proto.CommandExecute, ctx.Stmt.PrepareStmt)
 	result, warn, err := l.executor.ExecutorComStmtExecute(ctx)
+	if c.process.End() && err != nil {
+		err = errQueryInterrupted()
+	}
+	l.updateStatusFlags(c, ctx)
 	if err != nil {
 		if wErr := c.writeErrorPacketFromError(err); wErr != nil {
 			log.Error("Error writing query error to client %v: %v", l.connectionID, wErr)
@@ -173,6 +288,14 @@ func (l *Listener) handleStmtExecute(c *Conn, ctx *proto.Context) error {
 		// struct here since clients expect it.
 		return c.writeOKPacket(rlt.AffectedRows, rlt.InsertId, c.StatusFlags, warn)
 	}
+	if cursorType&mysql.CursorTypeReadOnly != 0 {
+		// the rows will be fetched by COM_STMT_FETCH
+		if c.cursors == nil {
+			c.cursors = make(map[uint32]*cursor)
+		}
+		c.cursors[stmtID] = newCursor(rlt)
+		return c.writeCursorFields(l.capabilities, result, warn)
+	}
 	if err = c.writeFields(l.capabilities, result); err != nil {
 		return err
 	}
@@ -217,6 +340,10 @@ func (l *Listener) handlePrepare(c *Conn, ctx *proto.Context) error {
 	}
 
 	l.stmts.Store(statementID, stmt)
+	if c.stmts == nil {
+		c.stmts = make(map[uint32]struct{})
+	}
+	c.stmts[statementID] = struct{}{}
 
 	return c.writePrepare(l.capabilities, stmt)
 }
@@ -225,38 +352,205 @@ func (l *Listener) handleStmtReset(c *Conn, ctx *proto.Context) error {
 	stmtID, _, ok := readUint32(ctx.Data, 1)
 	c.recycleReadPacket()
 	if ok {
+		if _, ok = c.stmts[stmtID]; !ok {
+			err := errors.NewSQLError(mysql.ERUnknownStmtHandler, mysql.SSUnknownSQLState,
+				"Unknown prepared statement handler (%d) given to mysqld_stmt_reset", stmtID)
+			return c.writeErrorPacketFromError(err)
+		}
 		if prepare, ok := l.stmts.Load(stmtID); ok {
 			prepareStmt, _ := prepare.(*proto.Stmt)
 			prepareStmt.BindVars = make(map[string]interface{})
 		}
+		delete(c.cursors, stmtID)
+		delete(c.longData, stmtID)
 	}
 	return c.writeOKPacket(0, 0, c.StatusFlags, 0)
 }
 
+// handleStmtSendLongData appends the chunk to the parameter, which will be bound by the next COM_STMT_EXECUTE.
+func (l *Listener) handleStmtSendLongData(c *Conn, ctx *proto.Context) {
+	stmtID, pos, ok := readUint32(ctx.Data, 1)
+	var paramID uint16
+	if ok {
+		paramID, pos, ok = readUint16(ctx.Data, pos)
+	}
+	c.recycleReadPacket()
+	if !ok {
+		log.Errorf("Conn %v: error parsing COM_STMT_SEND_LONG_DATA", c)
+		return
+	}
+
+	// there is no response, so the unknown statement or parameter is just ignored
+	var prepare interface{}
+	if _, ok = c.stmts[stmtID]; ok {
+		prepare, ok = l.stmts.Load(stmtID)
+	}
+	if !ok {
+		log.Warnf("Conn %v: COM_STMT_SEND_LONG_DATA for unknown statement %d", c, stmtID)
+		return
+	}
+	if paramID >= prepare.(*proto.Stmt).ParamsCount {
+		log.Warnf("Conn %v: COM_STMT_SEND_LONG_DATA for unknown parameter %d of statement %d", c, paramID, stmtID)
+		return
+	}
+
+	if c.longData == nil {
+		c.longData = make(map[uint32]map[uint16][]byte)
+	}
+	params, ok := c.longData[stmtID]
+	if !ok {
+		params = make(map[uint16][]byte)
+		c.longData[stmtID] = params
+	}
+	data, ok := params[paramID]
+	if !ok {
+		// the empty long data is bound as an empty value
+		data = make([]byte, 0, len(ctx.Data)-pos)
+	}
+	params[paramID] = append(data, ctx.Data[pos:]...)
+}
+
+func (l *Listener) handleStmtFetch(c *Conn, ctx *proto.Context) error {
+	c.startWriterBuffering()
+	defer func() {
+		if err := c.endWriterBuffering(); err != nil {
+			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
+		}
+	}()
+
+	stmtID, pos, ok := readUint32(ctx.Data, 1)
+	var numRows uint32
+	if ok {
+		numRows, _, ok = readUint32(ctx.Data, pos)
+	}
+	c.recycleReadPacket()
+
+	var err error
+	cur, exist := c.cursors[stmtID]
+	switch {
+	case !ok:
+		err = errors.NewSQLError(mysql.CRMalformedPacket, mysql.SSUnknownSQLState, "error parsing COM_STMT_FETCH")
+	case !exist:
+		err = errors.NewSQLError(mysql.ERStmtHasNoOpenCursor, mysql.SSUnknownSQLState, "The statement (%d) has no open cursor.", stmtID)
+	}
+	if err != nil {
+		if wErr := c.writeErrorPacketFromError(err); wErr != nil {
+			log.Errorf("Error writing ComStmtFetch error to %s: %v", c, wErr)
+			return wErr
+		}
+		return nil
+	}
+
+	rows, last := cur.fetch(numRows)
+	if err = c.writeBinaryRowsOf(rows); err != nil {
+		return err
+	}
+
+	flags := c.StatusFlags | mysql.ServerStatusCursorExists
+	if last {
+		// the cursor is closed after the last row is sent
+		flags |= mysql.ServerStatusLastRowSent
+		delete(c.cursors, stmtID)
+	}
+	if err = c.writeEndPacket(l.capabilities, flags, 0, 0, 0); err != nil {
+		log.Errorf("Error writing ComStmtFetch result to %s: %v", c, err)
+		return err
+	}
+	return nil
+}
+
+// handleChangeUser re-authenticates the connection, and then resets it like COM_RESET_CONNECTION.
+// The connection will be closed if failed to change user.
+func (l *Listener) handleChangeUser(c *Conn, ctx *proto.Context) error {
+	handshake, err := parseChangeUserPacket(c.clientFlags, ctx.Data)
+	c.recycleReadPacket()
+	if err != nil {
+		log.Errorf("Cannot parse COM_CHANGE_USER from %s: %v", c, err)
+		return writeChangeUserError(c, err)
+	}
+
+	handshake.connectionID = c.ConnectionID
+	handshake.salt = c.salt
+	_, handshake.secure = c.conn.(*tls.Conn)
+	handshake.peerCerts = c.GetTLSClientCerts()
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+		handshake.clientIP = addr.IP.String()
+	}
+
+	if err = l.authenticate(c, handshake); err != nil {
+		log.Errorf("Error changing user using %s: %v", handshake.authMethod, err)
+		return writeChangeUserError(c, err)
+	}
+
+	// discard the state of previous user, and then switch the quota to the new user
+	l.resetConnection(c, ctx)
+	l.release(c)
+	c.Schema = handshake.schema
+	c.Tenant = handshake.tenant
+	c.Username = handshake.username
+	c.process.SetUser(c.Tenant, c.Username, c.Schema)
+	if err = l.acquire(c); err != nil {
+		return writeChangeUserError(c, err)
+	}
+
+	if err = c.writeOKPacket(0, 0, c.StatusFlags, 0); err != nil {
+		log.Errorf("Error writing ComChangeUser result to %s: %v", c, err)
+		return err
+	}
+	return nil
+}
+
+// writeChangeUserError writes the error of COM_CHANGE_USER, and returns it to close the connection.
+func writeChangeUserError(c *Conn, err error) error {
+	if wErr := c.writeErrorPacketFromError(err); wErr != nil {
+		log.Errorf("Cannot write error packet to %s: %v", c, wErr)
+		return wErr
+	}
+	return err
+}
+
+func (l *Listener) handleResetConnection(c *Conn, ctx *proto.Context) error {
+	c.recycleReadPacket()
+	l.resetConnection(c, ctx)
+	if err := c.writeOKPacket(0, 0, c.StatusFlags, 0); err != nil {
+		log.Errorf("Error writing ComResetConnection result to %s: %v", c, err)
+		return err
+	}
+	return nil
+}
+
+// resetConnection rolls back the transaction, and clears the prepared statements and the session state.
+func (l *Listener) resetConnection(c *Conn, ctx *proto.Context) {
+	l.executor.ConnectionClose(ctx)
+	l.closeStmts(c)
+	c.session = proto.NewSession()
+	c.StatusFlags = initClientConnStatus
+}
+
 func (l *Listener) handleSetOption(c *Conn, ctx *proto.Context) error {
 	operation, _, ok := readUint16(ctx.Data, 1)
 	c.recycleReadPacket()
 	if ok {
+		// the option only affects the current connection
 		switch operation {
 		case 0:
-			l.capabilities |= mysql.CapabilityClientMultiStatements
+			c.clientFlags |= mysql.CapabilityClientMultiStatements
 		case 1:
-			l.capabilities &^= mysql.CapabilityClientMultiStatements
+			c.clientFlags &^= mysql.CapabilityClientMultiStatements
 		default:
-			log.Errorf("Got unhandled packet (ComSetOption default) from client %v, returning error: %v", l.connectionID, ctx.Data)
-			if err := c.writeErrorPacket(mysql.ERUnknownComError, mysql.SSUnknownComError, "error handling packet: %v", ctx.Data); err != nil {
-				log.Errorf("Error writing error packet to client: %v", err)
-				return err
-			}
+			ok = false
 		}
-		if err := c.writeEndResult(l.capabilities, false, 0, 0, 0); err != nil {
-			log.Errorf("Error writeEndResult error %v ", err)
+	}
+	if !ok {
+		log.Errorf("Got unhandled packet (ComSetOption) from client %v, returning error: %v", c.ConnectionID, ctx.Data)
+		if err := c.writeErrorPacket(mysql.ERUnknownComError, mysql.SSUnknownComError, "error handling packet: %v", ctx.Data); err != nil {
+			log.Errorf("Error writing error packet to client: %v", err)
 			return err
 		}
+		return nil
 	}
-	log.Errorf("Got unhandled packet (ComSetOption else) from client %v, returning error: %v", l.connectionID, ctx.Data)
-	if err := c.writeErrorPacket(mysql.ERUnknownComError, mysql.SSUnknownComError, "error handling packet: %v", ctx.Data); err != nil {
-		log.Errorf("Error writing error packet to client: %v", err)
+	if err := c.writeEndResult(l.capabilities, false, 0, 0, 0); err != nil {
+		log.Errorf("Error writeEndResult error %v ", err)
 		return err
 	}
 	return nil
@@ -0,0 +1,263 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+// fakeQueryExecutor records the executed statements, the statements containing 'fail' will fail,
+// the statements containing 'sleep' will be blocked until killed,
+// and the statements containing 'wait' will be blocked until wait is closed.
+type fakeQueryExecutor struct {
+	proto.Executor
+
+	mu       sync.Mutex
+	executed []string
+	wait     chan struct{}
+	// closed is the count of ConnectionClose calls
+	closed int
+	// bindVars is the bind variables of last executed statement
+	bindVars map[string]interface{}
+}
+
+func (f *fakeQueryExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Result, uint16, error) {
+	query := ctx.GetQuery()
+
+	f.mu.Lock()
+	f.executed = append(f.executed, query)
+	f.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "sleep"):
+		<-ctx.Context.Done()
+		return nil, 0, ctx.Context.Err()
+	case strings.Contains(query, "wait"):
+		<-f.wait
+		return &Result{AffectedRows: 1}, 0, nil
+	case strings.Contains(query, "fail"):
+		return nil, 0, errors.NewSQLError(mysql.ERUnknownError, mysql.SSUnknownSQLState, "fake error")
+	case strings.HasPrefix(query, "select"):
+		rs := &ResultSet{Columns: []proto.Field{&Field{name: "1", fieldType: mysql.FieldTypeVarString}}}
+		return &Result{
+			Fields: rs.Columns,
+			Rows:   []proto.Row{&Row{Content: []byte{1, '1'}, ResultSet: rs}},
+		}, 0, nil
+	default:
+		return &Result{AffectedRows: 1}, 0, nil
+	}
+}
+
+// ExecutorComStmtExecute records the bind variables, and returns 3 binary rows of column 'a', which are '0', '1' and '2'.
+func (f *fakeQueryExecutor) ExecutorComStmtExecute(ctx *proto.Context) (proto.Result, uint16, error) {
+	f.mu.Lock()
+	f.bindVars = make(map[string]interface{}, len(ctx.Stmt.BindVars))
+	for k, v := range ctx.Stmt.BindVars {
+		f.bindVars[k] = v
+	}
+	f.mu.Unlock()
+
+	rs := &ResultSet{Columns: []proto.Field{&Field{name: "a", fieldType: mysql.FieldTypeVarString}}}
+	rows := make([]proto.Row, 0, 3)
+	for i := 0; i < 3; i++ {
+		// header, NULL-bitmap and the length encoded value
+		rows = append(rows, &Row{Content: []byte{0x00, 0x00, 1, byte('0' + i)}, ResultSet: rs})
+	}
+	return &Result{Fields: rs.Columns, Rows: rows}, 0, nil
+}
+
+func (f *fakeQueryExecutor) InLocalTransaction(*proto.Context) bool {
+	return false
+}
+
+func (f *fakeQueryExecutor) ConnectionClose(*proto.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed++
+}
+
+func (f *fakeQueryExecutor) reset() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	executed := f.executed
+	f.executed = nil
+	return executed
+}
+
+func TestMultiStatements(t *testing.T) {
+	const (
+		tenant  = "fake_multi_stmt_tenant"
+		cluster = "fake_multi_stmt_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	executor := &fakeQueryExecutor{}
+	l.SetExecutor(executor)
+	go l.Listen()
+	defer l.listener.Close()
+
+	open := func(multiStatements bool) *sql.DB {
+		cfg := driver.NewConfig()
+		cfg.User, cfg.Passwd, cfg.DBName = "foo", "123456", cluster
+		cfg.Net, cfg.Addr = "tcp", l.listener.Addr().String()
+		cfg.MultiStatements = multiStatements
+		connector, err := driver.NewConnector(cfg)
+		assert.NoError(t, err)
+		db := sql.OpenDB(connector)
+		db.SetMaxOpenConns(1)
+		return db
+	}
+
+	db := open(true)
+	defer db.Close()
+
+	_, err = db.Exec("insert into t values (1); insert into t values (';');")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insert into t values (1)", "insert into t values (';')"}, executor.reset())
+
+	rows, err := db.QueryContext(context.Background(), "select 1; insert into t values (1); select 1")
+	assert.NoError(t, err)
+	var results int
+	for {
+		for rows.Next() {
+			var v string
+			assert.NoError(t, rows.Scan(&v))
+			assert.Equal(t, "1", v)
+			results++
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	assert.NoError(t, rows.Err())
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, 2, results)
+	assert.Len(t, executor.reset(), 3)
+
+	// the execution stops on the first error
+	_, err = db.Exec("insert into t values (1); delete from fail; insert into t values (2)")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"insert into t values (1)", "delete from fail"}, executor.reset())
+
+	// multiple statements are rejected if the client doesn't enable it
+	single := open(false)
+	defer single.Close()
+	_, err = single.Exec("insert into t values (1); insert into t values (2)")
+	var mysqlErr *driver.MySQLError
+	if assert.ErrorAs(t, err, &mysqlErr) {
+		assert.Equal(t, uint16(mysql.ERParseError), mysqlErr.Number)
+	}
+	assert.Empty(t, executor.reset())
+	_, err = single.Exec("insert into t values (1);")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insert into t values (1);"}, executor.reset())
+	// the flag negotiated by other connections doesn't matter
+	_, err = db.Exec("insert into t values (1); insert into t values (2)")
+	assert.NoError(t, err)
+	assert.Len(t, executor.reset(), 2)
+}
+
+func TestSetOption(t *testing.T) {
+	const (
+		tenant  = "fake_set_option_tenant"
+		cluster = "fake_set_option_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	executor := &fakeQueryExecutor{}
+	l.SetExecutor(executor)
+	go l.Listen()
+	defer l.listener.Close()
+
+	// connect returns a connection with multiple statements enabled
+	connect := func() *BackendConnection {
+		connector, err := NewConnector([]byte(fmt.Sprintf(`{"dsn": "foo:123456@tcp(%s)/%s"}`, l.listener.Addr(), cluster)))
+		assert.NoError(t, err)
+		res, err := connector.NewBackendConnection(context.Background())
+		assert.NoError(t, err)
+		return res.(*BackendConnection)
+	}
+	setOption := func(bc *BackendConnection, option byte) {
+		bc.c.sequence = 0
+		assert.NoError(t, bc.c.writePacket([]byte{mysql.ComSetOption, option, 0}))
+		response, err := bc.c.readPacket()
+		assert.NoError(t, err)
+		assert.False(t, isErrorPacket(response))
+	}
+
+	first, second := connect(), connect()
+	defer first.Close()
+	defer second.Close()
+
+	// disable multiple statements of the first connection only
+	setOption(first, 1)
+	_, err = first.Execute("insert into t values (1); insert into t values (2)", true)
+	if assert.IsType(t, (*errors.SQLError)(nil), err) {
+		assert.Equal(t, mysql.ERParseError, err.(*errors.SQLError).Num)
+	}
+	_, err = second.Execute("insert into t values (1); insert into t values (2)", true)
+	assert.NoError(t, err)
+	assert.Len(t, executor.reset(), 2)
+
+	setOption(first, 0)
+	_, err = first.Execute("insert into t values (1); insert into t values (2)", true)
+	assert.NoError(t, err)
+	assert.Len(t, executor.reset(), 2)
+}
+
+func TestParseEmptyQuery(t *testing.T) {
+	l := &Listener{}
+	_, err := l.parseQuery(&Conn{}, &proto.Context{Context: context.Background(), Data: []byte{mysql.ComQuery, ' ', ';'}})
+	assert.Error(t, err)
+	assert.Equal(t, mysql.EREmptyQuery, err.(*errors.SQLError).Num)
+}
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+import (
+	"github.com/arana-db/parser/ast"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// _processInfoLength is the max length of info shown by 'SHOW PROCESSLIST' without FULL.
+const _processInfoLength = 100
+
+var _processListColumns = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info", "Tenant"}
+
+// showProcessList lists the connections of the same tenant.
+func (l *Listener) showProcessList(c *Conn, full bool) proto.Result {
+	var processes []proto.ProcessInfo
+	l.conns.Range(func(_, value interface{}) bool {
+		// the tenant of other connection could be changed by COM_CHANGE_USER concurrently
+		if it := value.(*Conn); it.process.Tenant() == c.Tenant {
+			processes = append(processes, it.process.Info())
+		}
+		return true
+	})
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].ID < processes[j].ID
+	})
+
+	fields := make([]proto.Field, 0, len(_processListColumns))
+	for _, it := range _processListColumns {
+		fields = append(fields, NewField(it))
+	}
+
+	rows := make([]proto.Row, 0, len(processes))
+	for _, it := range processes {
+		info := it.Info
+		if !full && len(info) > _processInfoLength {
+			info = info[:_processInfoLength]
+		}
+		values := []*proto.Value{
+			processValue(strconv.FormatUint(uint64(it.ID), 10)),
+			processValue(it.Username),
+			processValue(it.Host),
+			processValue(it.Schema),
+			processValue(it.Command),
+			processValue(strconv.FormatInt(int64(it.Time.Seconds()), 10)),
+			processValue(it.State),
+			processValue(info),
+			processValue(it.Tenant),
+		}
+		rows = append(rows, (&Row{}).Encode(values, fields, _processListColumns))
+	}
+
+	return &Result{Fields: fields, Rows: rows}
+}
+
+// processValue encodes the value of process list, the empty string is encoded as NULL.
+func processValue(s string) *proto.Value {
+	raw := []byte{mysql.NullValue}
+	if len(s) > 0 {
+		raw = PutLengthEncodedString([]byte(s))
+	}
+	return &proto.Value{Raw: raw, Len: len(raw)}
+}
+
+// kill kills the running query of connection, and closes the connection if it's not 'KILL QUERY'.
+// Only the connections of the same tenant can be killed.
+func (l *Listener) kill(c *Conn, stmt *ast.KillStmt) error {
+	var target *Conn
+	if stmt.ConnectionID <= math.MaxUint32 {
+		if v, ok := l.conns.Load(uint32(stmt.ConnectionID)); ok {
+			target = v.(*Conn)
+		}
+	}
+	if target == nil || target.process.Tenant() != c.Tenant {
+		return errors.NewSQLError(mysql.ERNoSuchThread, mysql.SSUnknownSQLState, "Unknown thread id: %d", stmt.ConnectionID)
+	}
+
+	target.process.KillQuery()
+	if !stmt.Query {
+		target.Close()
+	}
+	return nil
+}
+
+func errQueryInterrupted() error {
+	return errors.NewSQLError(mysql.ERQueryInterrupted, mysql.SSUnknownSQLState, "Query execution was interrupted")
+}
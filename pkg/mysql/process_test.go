@@ -0,0 +1,152 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+func TestProcessListAndKill(t *testing.T) {
+	const (
+		tenant  = "fake_process_tenant"
+		cluster = "fake_process_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	executor := &fakeQueryExecutor{}
+	l.SetExecutor(executor)
+	go l.Listen()
+	defer l.listener.Close()
+
+	cfg := driver.NewConfig()
+	cfg.User, cfg.Passwd, cfg.DBName = "foo", "123456", cluster
+	cfg.Net, cfg.Addr = "tcp", l.listener.Addr().String()
+	connector, err := driver.NewConnector(cfg)
+	assert.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	sleeping, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer sleeping.Close()
+	admin, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer admin.Close()
+
+	// findSleeping returns the id of connection which is running 'sleep', or -1 if not found
+	findSleeping := func() int64 {
+		rows, err := admin.QueryContext(ctx, "SHOW FULL PROCESSLIST")
+		if !assert.NoError(t, err) {
+			return -1
+		}
+		defer rows.Close()
+		columns, _ := rows.Columns()
+		assert.Equal(t, _processListColumns, columns)
+
+		id := int64(-1)
+		for rows.Next() {
+			var (
+				pid                            int64
+				user, host, command, tenantCol string
+				schema, state, info            sql.NullString
+				seconds                        int64
+			)
+			assert.NoError(t, rows.Scan(&pid, &user, &host, &schema, &command, &seconds, &state, &info, &tenantCol))
+			assert.Equal(t, "foo", user)
+			assert.Equal(t, cluster, schema.String)
+			assert.Equal(t, tenant, tenantCol)
+			if info.String == "select sleep(100)" {
+				assert.Equal(t, "Query", command)
+				assert.Equal(t, "executing", state.String)
+				id = pid
+			}
+		}
+		assert.NoError(t, rows.Err())
+		return id
+	}
+
+	// KILL QUERY interrupts the running statement
+	done := make(chan error, 1)
+	go func() {
+		_, err := sleeping.ExecContext(ctx, "select sleep(100)")
+		done <- err
+	}()
+
+	var id int64
+	assert.Eventually(t, func() bool {
+		id = findSleeping()
+		return id >= 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = admin.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id))
+	assert.NoError(t, err)
+
+	select {
+	case err = <-done:
+		var mysqlErr *driver.MySQLError
+		if assert.ErrorAs(t, err, &mysqlErr) {
+			assert.Equal(t, uint16(mysql.ERQueryInterrupted), mysqlErr.Number)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query is not killed")
+	}
+
+	// the connection is still alive
+	assert.NoError(t, sleeping.PingContext(ctx))
+	assert.Equal(t, int64(-1), findSleeping())
+
+	// unknown connection
+	_, err = admin.ExecContext(ctx, "KILL 4294967296")
+	var mysqlErr *driver.MySQLError
+	if assert.ErrorAs(t, err, &mysqlErr) {
+		assert.Equal(t, uint16(mysql.ERNoSuchThread), mysqlErr.Number)
+	}
+
+	// KILL closes the connection
+	_, err = admin.ExecContext(ctx, fmt.Sprintf("KILL %d", id))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, ok := l.conns.Load(uint32(id))
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
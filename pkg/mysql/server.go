@@ -18,9 +18,11 @@
 package mysql
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"math"
@@ -35,15 +37,21 @@ import (
 
 	err2 "github.com/pkg/errors"
 
+	oteltrace "go.opentelemetry.io/otel/trace"
+
 	"go.uber.org/atomic"
 )
 
 import (
 	"github.com/arana-db/arana/pkg/config"
 	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/metrics"
 	"github.com/arana-db/arana/pkg/mysql/errors"
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/quota"
+	rcontext "github.com/arana-db/arana/pkg/runtime/context"
 	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -57,10 +65,20 @@ type handshakeResult struct {
 	authMethod   string
 	authResponse []byte
 	salt         []byte
+	clientFlags  uint32
+	// sslRequest is true if the client requests to switch to TLS.
+	sslRequest bool
+	// secure is true if the connection is over TLS.
+	secure bool
+	// peerCerts is the verified client certificates.
+	peerCerts []*x509.Certificate
+	clientIP  string
+	connAttrs map[string]string
 }
 
 type ServerConfig struct {
-	ServerVersion string `yaml:"server_version" json:"server_version"`
+	ServerVersion     string `yaml:"server_version" json:"server_version"`
+	DefaultAuthPlugin string `yaml:"default_auth_plugin" json:"default_auth_plugin"`
 }
 
 type Listener struct {
@@ -70,6 +88,14 @@ type Listener struct {
 	// This is the main listener socket.
 	listener net.Listener
 
+	// tlsConfig is the TLS config for client connections, TLS is disabled if nil.
+	tlsConfig *tls.Config
+
+	// rsaKey is used to exchange passwords without TLS, it's generated on demand if not configured.
+	rsaKey     *rsa.PrivateKey
+	rsaKeyOnce sync.Once
+	rsaKeyErr  error
+
 	executor proto.Executor
 
 	// Incrementing ID for connection id.
@@ -106,11 +132,38 @@ type Listener struct {
 	// stmts is the map to use a prepared statement.
 	// key is uint32 value is *proto.Stmt
 	stmts sync.Map
+
+	// conns is the registry of authenticated connections.
+	// key is uint32 value is *Conn
+	conns sync.Map
+
+	// mu guards draining and the busy flags of connections.
+	mu sync.Mutex
+	// draining is true if the listener is shutting down.
+	draining bool
+	// handlers tracks the goroutines serving connections.
+	handlers sync.WaitGroup
 }
 
 func NewListener(conf *config.Listener) (proto.Listener, error) {
 	cfg := &ServerConfig{
-		ServerVersion: conf.ServerVersion,
+		ServerVersion:     conf.ServerVersion,
+		DefaultAuthPlugin: conf.DefaultAuthPlugin,
+	}
+	switch cfg.DefaultAuthPlugin {
+	case "":
+		cfg.DefaultAuthPlugin = mysql.MysqlNativePassword
+	case mysql.MysqlNativePassword, mysql.CachingSha2Password, mysql.Sha256Password:
+	default:
+		return nil, err2.Errorf("unsupported auth plugin '%s'", cfg.DefaultAuthPlugin)
+	}
+
+	var rsaKey *rsa.PrivateKey
+	if len(conf.RSAPrivateKey) > 0 {
+		var err error
+		if rsaKey, err = loadRSAPrivateKey(conf.RSAPrivateKey); err != nil {
+			return nil, err
+		}
 	}
 
 	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.SocketAddress.Address, conf.SocketAddress.Port))
@@ -122,6 +175,14 @@ func NewListener(conf *config.Listener) (proto.Listener, error) {
 	listener := &Listener{
 		conf:     cfg,
 		listener: l,
+		rsaKey:   rsaKey,
+	}
+
+	if conf.TLS != nil {
+		if listener.tlsConfig, err = newServerTLSConfig(conf.TLS); err != nil {
+			_ = l.Close()
+			return nil, err
+		}
 	}
 	return listener, nil
 }
@@ -138,6 +199,11 @@ func (l *Listener) Listen() {
 			return
 		}
 
+		if !l.accept() {
+			_ = conn.Close()
+			return
+		}
+
 		connectionID := l.connectionID
 		l.connectionID++
 
@@ -145,12 +211,114 @@ func (l *Listener) Listen() {
 	}
 }
 
+// Close stops accepting new connections, and closes all connections immediately.
 func (l *Listener) Close() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Shutdown(ctx)
+}
+
+// Shutdown stops accepting new connections, and closes the idle connections. The busy connections will be closed
+// once their commands are finished, and their statements will be killed if ctx is done before that.
+// The open transactions will be rolled back when the connections are closed.
+func (l *Listener) Shutdown(ctx context.Context) {
+	l.mu.Lock()
+	l.draining = true
+	l.mu.Unlock()
+
+	if err := l.listener.Close(); err != nil {
+		log.Warnf("failed to close mysql Listener %s: %v", l.listener.Addr(), err)
+	}
+	l.closeConns(false)
+
+	done := make(chan struct{})
+	go func() {
+		l.handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	log.Warnf("mysql Listener %s is not drained in time, close all connections", l.listener.Addr())
+	l.closeConns(true)
+	<-done
+}
+
+func (l *Listener) writeShutdownError(c *Conn) {
+	if err := c.writeErrorPacket(mysql.ERServerShutdown, mysql.SSServerShutdown, "Server shutdown in progress"); err != nil {
+		log.Errorf("Cannot write error packet to %s: %v", c, err)
+	}
+}
+
+// closeConns closes the idle connections, and kills the statements of busy connections if force is true.
+func (l *Listener) closeConns(force bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conns.Range(func(_, value interface{}) bool {
+		c := value.(*Conn)
+		switch {
+		case !c.busy:
+			c.Close()
+		case force:
+			c.process.KillQuery()
+			c.Close()
+		}
+		return true
+	})
+}
+
+// accept tracks the goroutine serving new connection, returns false if the listener is shutting down.
+func (l *Listener) accept() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.draining {
+		return false
+	}
+	l.handlers.Add(1)
+	return true
+}
+
+// register registers the authenticated connection, returns false if the listener is shutting down.
+func (l *Listener) register(c *Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.draining {
+		return false
+	}
+	l.conns.Store(c.ConnectionID, c)
+	return true
+}
+
+// beginCommand marks the connection as busy, returns false if the listener is shutting down.
+func (l *Listener) beginCommand(c *Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.draining {
+		return false
+	}
+	c.busy = true
+	return true
+}
+
+// endCommand marks the connection as idle, returns false if the listener is shutting down.
+func (l *Listener) endCommand(c *Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c.busy = false
+	return !l.draining
 }
 
 func (l *Listener) handle(conn net.Conn, connectionID uint32) {
+	defer l.handlers.Done()
+
 	c := newConn(conn)
 	c.ConnectionID = connectionID
+	c.session = proto.NewSession()
+	c.StatusFlags = initClientConnStatus
 
 	// Catch panics, and close the connection in any case.
 	defer func() {
@@ -160,8 +328,8 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 
 		conn.Close()
 		l.executor.ConnectionClose(&proto.Context{
-			Context:      context.Background(),
-			ConnectionID: l.connectionID,
+			Context:      rcontext.WithSession(context.Background(), c.session),
+			ConnectionID: c.ConnectionID,
 		})
 	}()
 
@@ -174,6 +342,22 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 		return
 	}
 
+	if err = l.acquire(c); err != nil {
+		if wErr := c.writeErrorPacketFromError(err); wErr != nil {
+			log.Errorf("Cannot write error packet to %s: %v", c, wErr)
+		}
+		return
+	}
+	defer l.release(c)
+	defer l.closeStmts(c)
+
+	c.process = proto.NewProcess(c.ConnectionID, c.Tenant, c.Username, c.RemoteAddr().String(), c.Schema)
+	if !l.register(c) {
+		l.writeShutdownError(c)
+		return
+	}
+	defer l.conns.Delete(c.ConnectionID)
+
 	// Negotiation worked, send OK packet.
 	if err = c.writeOKPacket(0, 0, c.StatusFlags, 0); err != nil {
 		log.Errorf("Cannot write OK packet to %s: %v", c, err)
@@ -191,27 +375,71 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32) {
 			return
 		}
 
+		// the command is discarded if the listener is shutting down
+		if !l.beginCommand(c) {
+			c.recycleReadPacket()
+			l.writeShutdownError(c)
+			return
+		}
+
 		content := make([]byte, len(data))
 		copy(content, data)
 		ctx := &proto.Context{
-			Context:      context.Background(),
+			Context:      rcontext.WithSession(context.Background(), c.session),
 			Schema:       c.Schema,
-			ConnectionID: l.connectionID,
+			Tenant:       c.Tenant,
+			Username:     c.Username,
+			RemoteAddr:   c.RemoteAddr().String(),
+			ConnectionID: c.ConnectionID,
 			Data:         content,
 		}
-		if err = l.ExecuteCommand(c, ctx); err != nil {
+		err = l.ExecuteCommand(c, ctx)
+		if !l.endCommand(c) || err != nil {
 			return
 		}
 	}
 }
 
+// acquire acquires the quota of connection, and counts it by tenant.
+func (l *Listener) acquire(c *Conn) error {
+	release, err := quota.AcquireConn(c.Tenant, c.Username)
+	if err != nil {
+		return err
+	}
+	connections := metrics.FrontendConnections.WithLabelValues(l.listener.Addr().String(), c.Tenant)
+	connections.Inc()
+	c.release = func() {
+		connections.Dec()
+		release()
+	}
+	return nil
+}
+
+// release releases the quota acquired by acquire, it does nothing if nothing acquired.
+func (l *Listener) release(c *Conn) {
+	if c.release != nil {
+		c.release()
+		c.release = nil
+	}
+}
+
+// closeStmts closes all prepared statements and cursors of connection.
+func (l *Listener) closeStmts(c *Conn) {
+	for id := range c.stmts {
+		l.stmts.Delete(id)
+	}
+	c.stmts = nil
+	c.cursors = nil
+	c.longData = nil
+}
+
 func (l *Listener) handshake(c *Conn) error {
 	salt, err := newSalt()
 	if err != nil {
 		return err
 	}
 	// First build and send the server handshake packet.
-	err = l.writeHandshakeV10(c, false, salt)
+	err = l.writeHandshakeV10(c, l.tlsConfig != nil, salt)
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -237,17 +465,50 @@ func (l *Listener) handshake(c *Conn) error {
 		log.Errorf("Cannot parse client handshake response from %s: %v", c, err)
 		return err
 	}
+
+	if handshake.sslRequest {
+		// Switch to TLS, and then re-read the full handshake response.
+		conn := tls.Server(c.conn, l.tlsConfig)
+		if err = conn.Handshake(); err != nil {
+			log.Infof("Cannot complete TLS handshake with %s: %v", c, err)
+			return err
+		}
+		c.conn = conn
+		c.bufferedReader.Reset(conn)
+
+		if response, err = c.readEphemeralPacketDirect(); err != nil {
+			if err != io.EOF {
+				log.Infof("Cannot read client handshake response from %s: %v", c, err)
+			}
+			return err
+		}
+		c.recycleReadPacket()
+
+		if handshake, err = l.parseClientHandshakePacket(false, response); err != nil {
+			log.Errorf("Cannot parse client handshake response from %s: %v", c, err)
+			return err
+		}
+		handshake.secure = true
+		handshake.peerCerts = c.GetTLSClientCerts()
+	}
+
 	handshake.connectionID = c.ConnectionID
 	handshake.salt = salt
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+		handshake.clientIP = addr.IP.String()
+	}
 
-	err = l.ValidateHash(handshake)
+	err = l.authenticate(c, handshake)
 	if err != nil {
-		log.Errorf("Error authenticating user using MySQL native password: %v", err)
+		log.Errorf("Error authenticating user using %s: %v", handshake.authMethod, err)
 		return err
 	}
 
 	c.Schema = handshake.schema
 	c.Tenant = handshake.tenant
+	c.Username = handshake.username
+	c.salt = salt
+	c.clientFlags = handshake.clientFlags
 
 	return nil
 }
@@ -285,7 +546,7 @@ func (l *Listener) writeHandshakeV10(c *Conn, enableTLS bool, salt []byte) error
 			1 + // length of auth plugin Content
 			10 + // reserved (0)
 			13 + // auth-plugin-Content
-			lenNullString(mysql.MysqlNativePassword) // auth-plugin-name
+			lenNullString(l.conf.DefaultAuthPlugin) // auth-plugin-name
 
 	data := c.startEphemeralPacket(length)
 	pos := 0
@@ -328,8 +589,8 @@ func (l *Listener) writeHandshakeV10(c *Conn, enableTLS bool, salt []byte) error
 	data[pos] = 0
 	pos++
 
-	// Copy authPluginName. We always start with mysql_native_password.
-	pos = writeNullString(data, pos, mysql.MysqlNativePassword)
+	// Copy authPluginName, the client may switch to other auth methods.
+	pos = writeNullString(data, pos, l.conf.DefaultAuthPlugin)
 
 	// Sanity check.
 	if pos != len(data) {
@@ -371,11 +632,6 @@ func (l *Listener) parseClientHandshakePacket(firstTime bool, data []byte) (*han
 		l.capabilities = clientFlags & (mysql.CapabilityClientDeprecateEOF | mysql.CapabilityClientFoundRows)
 	}
 
-	// set connection capability for executing multi statements
-	if clientFlags&mysql.CapabilityClientMultiStatements > 0 {
-		l.capabilities |= mysql.CapabilityClientMultiStatements
-	}
-
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
@@ -393,15 +649,10 @@ func (l *Listener) parseClientHandshakePacket(firstTime bool, data []byte) (*han
 	// 23x reserved zero bytes.
 	pos += 23
 
-	//// Check for SSL.
-	//if firstTime && l.TLSConfig != nil && clientFlags&CapabilityClientSSL > 0 {
-	//	// Need to switch to TLS, and then re-read the packet.
-	//	conn := tls.Server(c.conn, l.TLSConfig)
-	//	c.conn = conn
-	//	c.bufferedReader.Reset(conn)
-	//	l.capabilities |= CapabilityClientSSL
-	//	return "", "", nil, nil
-	//}
+	// Check for SSL, the client sends the full handshake response after switching to TLS.
+	if firstTime && l.tlsConfig != nil && clientFlags&mysql.CapabilityClientSSL > 0 {
+		return &handshakeResult{sslRequest: true}, nil
+	}
 
 	// username
 	username, pos, ok := readNullString(data, pos)
@@ -468,44 +719,195 @@ func (l *Listener) parseClientHandshakePacket(firstTime bool, data []byte) (*han
 	}
 
 	// Decode connection attributes send by the client
+	var connAttrs map[string]string
 	if clientFlags&mysql.CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		var err error
+		if connAttrs, _, err = parseConnAttrs(data, pos); err != nil {
 			log.Warnf("Decode connection attributes send by the client: %v", err)
 		}
 	}
 
 	return &handshakeResult{
+		connAttrs:    connAttrs,
 		schema:       schemaName,
 		username:     username,
 		authMethod:   authMethod,
 		authResponse: authResponse,
+		clientFlags:  clientFlags,
 	}, nil
 }
 
-func (l *Listener) ValidateHash(handshake *handshakeResult) error {
-	tenant, ok := security.DefaultTenantManager().GetTenantOfCluster(handshake.schema)
+// parseChangeUserPacket parses the COM_CHANGE_USER packet, the client flags are the ones sent by handshake.
+func parseChangeUserPacket(clientFlags uint32, data []byte) (*handshakeResult, error) {
+	// skip the command byte
+	pos := 1
+
+	username, pos, ok := readNullString(data, pos)
 	if !ok {
-		return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", handshake.username)
+		return nil, err2.New("parseChangeUserPacket: can't read username")
 	}
 
-	user, ok := security.DefaultTenantManager().GetUser(tenant, handshake.username)
+	var authResponse []byte
+	if clientFlags&mysql.CapabilityClientSecureConnection != 0 {
+		var l byte
+		l, pos, ok = readByte(data, pos)
+		if !ok {
+			return nil, err2.New("parseChangeUserPacket: can't read auth-response length")
+		}
+		authResponse, pos, ok = readBytesCopy(data, pos, int(l))
+		if !ok {
+			return nil, err2.New("parseChangeUserPacket: can't read auth-response")
+		}
+	} else {
+		a := ""
+		a, pos, ok = readNullString(data, pos)
+		if !ok {
+			return nil, err2.New("parseChangeUserPacket: can't read auth-response")
+		}
+		authResponse = []byte(a)
+	}
+
+	schemaName, pos, ok := readNullString(data, pos)
 	if !ok {
-		return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", handshake.username)
+		return nil, err2.New("parseChangeUserPacket: can't read dbname")
+	}
+
+	// the following fields are optional, the character set is ignored like handshake.
+	if pos < len(data) {
+		if _, pos, ok = readUint16(data, pos); !ok {
+			return nil, err2.New("parseChangeUserPacket: can't read characterSet")
+		}
+	}
+
+	authMethod := mysql.MysqlNativePassword
+	if clientFlags&mysql.CapabilityClientPluginAuth != 0 && pos < len(data) {
+		authMethod, pos, ok = readNullString(data, pos)
+		if !ok {
+			return nil, err2.New("parseChangeUserPacket: can't read authMethod")
+		}
+	}
+	if authMethod == "" {
+		authMethod = mysql.MysqlNativePassword
+	}
+
+	var connAttrs map[string]string
+	if clientFlags&mysql.CapabilityClientConnAttr != 0 && pos < len(data) {
+		var err error
+		if connAttrs, _, err = parseConnAttrs(data, pos); err != nil {
+			log.Warnf("Decode connection attributes send by the client: %v", err)
+		}
+	}
+
+	return &handshakeResult{
+		connAttrs:    connAttrs,
+		schema:       schemaName,
+		username:     username,
+		authMethod:   authMethod,
+		authResponse: authResponse,
+		clientFlags:  clientFlags,
+	}, nil
+}
+
+func (l *Listener) ValidateHash(handshake *handshakeResult) error {
+	return l.verify(handshake, &scrambleCredential{salt: handshake.salt, authResponse: handshake.authResponse})
+}
+
+// verify authenticates the credential by the authenticator of tenant, and binds the tenant to handshake.
+func (l *Listener) verify(handshake *handshakeResult, cred security.Credential) error {
+	tm := security.DefaultTenantManager()
+	tenant, ok := tm.GetTenantOfCluster(handshake.schema)
+	if !ok {
+		return errAccessDenied(handshake)
+	}
+
+	bound, err := tm.GetAuthenticator(tenant).Authenticate(context.Background(), &security.AuthRequest{
+		Tenant:     tenant,
+		Username:   handshake.username,
+		Schema:     handshake.schema,
+		ClientIP:   handshake.clientIP,
+		AuthMethod: handshake.authMethod,
+		ConnAttrs:  handshake.connAttrs,
+	}, cred)
+	if err != nil {
+		if err != security.ErrAccessDenied {
+			log.Errorf("failed to authenticate user %s of tenant %s: %v", handshake.username, tenant, err)
+		}
+		return errAccessDenied(handshake)
 	}
 
-	computedAuthResponse := scramblePassword(handshake.salt, user.Password)
-	if !bytes.Equal(handshake.authResponse, computedAuthResponse) {
-		return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", handshake.username)
+	// the bound tenant must own the schema
+	if bound != tenant {
+		log.Warnf("user %s is bound to tenant %s, which doesn't own schema %s", handshake.username, bound, handshake.schema)
+		return errAccessDenied(handshake)
+	}
+
+	if err = checkTLS(bound, handshake); err != nil {
+		return err
 	}
+	handshake.tenant = bound
+	return nil
+}
+
+func errAccessDenied(handshake *handshakeResult) error {
+	return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", handshake.username)
+}
 
-	// bind tenant
-	handshake.tenant = tenant
+// checkTLS checks the TLS requirements of tenant and user.
+func checkTLS(tenant string, handshake *handshakeResult) error {
+	var user config.User
+	if exist, ok := security.DefaultTenantManager().GetUser(tenant, handshake.username); ok {
+		user = *exist
+	}
 
+	requireTLS := user.RequireTLS || len(user.CertSubject) > 0 || security.DefaultTenantManager().IsRequireTLS(tenant)
+	if requireTLS && !handshake.secure {
+		return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v', TLS is required", handshake.username)
+	}
+	if len(user.CertSubject) > 0 {
+		if len(handshake.peerCerts) < 1 || handshake.peerCerts[0].Subject.String() != user.CertSubject {
+			return errors.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v', invalid client certificate", handshake.username)
+		}
+	}
 	return nil
 }
 
-func (l *Listener) ExecuteCommand(c *Conn, ctx *proto.Context) error {
+// _commandNames is the names of supported commands, which are used as the names of spans.
+var _commandNames = map[byte]string{
+	mysql.ComInitDB:           "COM_INIT_DB",
+	mysql.ComQuery:            "COM_QUERY",
+	mysql.ComPing:             "COM_PING",
+	mysql.ComFieldList:        "COM_FIELD_LIST",
+	mysql.ComPrepare:          "COM_STMT_PREPARE",
+	mysql.ComStmtExecute:      "COM_STMT_EXECUTE",
+	mysql.ComStmtClose:        "COM_STMT_CLOSE",
+	mysql.ComStmtSendLongData: "COM_STMT_SEND_LONG_DATA",
+	mysql.ComStmtReset:        "COM_STMT_RESET",
+	mysql.ComStmtFetch:        "COM_STMT_FETCH",
+	mysql.ComSetOption:        "COM_SET_OPTION",
+	mysql.ComChangeUser:       "COM_CHANGE_USER",
+	mysql.ComResetConnection:  "COM_RESET_CONNECTION",
+}
+
+func (l *Listener) ExecuteCommand(c *Conn, ctx *proto.Context) (err error) {
 	commandType := ctx.Data[0]
+
+	if name, ok := _commandNames[commandType]; ok {
+		// the trace context could be passed by sql comment
+		if commandType == mysql.ComQuery || commandType == mysql.ComPrepare {
+			ctx.Context = trace.Extract(ctx.Context, string(ctx.Data[1:]))
+		}
+
+		var span oteltrace.Span
+		ctx.Context, span = trace.Start(ctx.Context, name,
+			trace.KeyConnectionID.Int64(int64(c.ConnectionID)),
+			trace.KeyTenant.String(c.Tenant),
+			trace.KeySchema.String(c.Schema),
+		)
+		defer func() {
+			trace.End(span, err)
+		}()
+	}
+
 	switch commandType {
 	case mysql.ComQuit:
 		// https://dev.mysql.com/doc/internals/en/com-quit.html
@@ -532,15 +934,25 @@ func (l *Listener) ExecuteCommand(c *Conn, ctx *proto.Context) error {
 	case mysql.ComStmtClose: // no response
 		stmtID, _, ok := readUint32(ctx.Data, 1)
 		c.recycleReadPacket()
-		if ok {
+		// the statement prepared by other connection cannot be closed
+		if _, owned := c.stmts[stmtID]; ok && owned {
 			l.stmts.Delete(stmtID)
+			delete(c.stmts, stmtID)
+			delete(c.cursors, stmtID)
+			delete(c.longData, stmtID)
 		}
 	case mysql.ComStmtSendLongData: // no response
-		// todo
+		l.handleStmtSendLongData(c, ctx)
 	case mysql.ComStmtReset:
 		return l.handleStmtReset(c, ctx)
+	case mysql.ComStmtFetch:
+		return l.handleStmtFetch(c, ctx)
 	case mysql.ComSetOption:
 		return l.handleSetOption(c, ctx)
+	case mysql.ComChangeUser:
+		return l.handleChangeUser(c, ctx)
+	case mysql.ComResetConnection:
+		return l.handleResetConnection(c, ctx)
 	}
 	return nil
 }
@@ -625,7 +1037,10 @@ func (c *Conn) parseComStmtExecute(stmts *sync.Map, data []byte) (uint32, byte,
 	if !ok {
 		return 0, 0, errors.NewSQLError(mysql.CRMalformedPacket, mysql.SSUnknownSQLState, "reading statement ID failed")
 	}
-	//prepare, ok := stmts[stmtID]
+	// the statement prepared by other connection is not visible
+	if _, ok = c.stmts[stmtID]; !ok {
+		return 0, 0, errors.NewSQLError(mysql.CRCommandsOutOfSync, mysql.SSUnknownSQLState, "statement ID is not found from record")
+	}
 	prepare, ok := stmts.Load(stmtID)
 	if !ok {
 		return 0, 0, errors.NewSQLError(mysql.CRCommandsOutOfSync, mysql.SSUnknownSQLState, "statement ID is not found from record")
@@ -677,6 +1092,13 @@ func (c *Conn) parseComStmtExecute(stmts *sync.Map, data []byte) (uint32, byte,
 		}
 	}
 
+	// the parameters sent by COM_STMT_SEND_LONG_DATA are not in the payload
+	for i, data := range c.longData[stmtID] {
+		if int(i) < len(prepareStmt.ParamsType) {
+			prepareStmt.BindVars[fmt.Sprintf("v%d", i+1)] = data
+		}
+	}
+
 	for i := 0; i < len(prepareStmt.ParamsType); i++ {
 		var val interface{}
 		parameterID := fmt.Sprintf("v%d", i+1)
@@ -1007,6 +1429,21 @@ func (c *Conn) writeFields(capabilities uint32, result proto.Result) error {
 	return nil
 }
 
+// writeCursorFields writes the fields of result which is read by cursor,
+// the fields are always ended with SERVER_STATUS_CURSOR_EXISTS, and the rows will be fetched by COM_STMT_FETCH.
+func (c *Conn) writeCursorFields(capabilities uint32, result proto.Result, warnings uint16) error {
+	fields := result.GetFields()
+	if err := c.sendColumnCount(uint64(len(fields))); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := c.writeColumnDefinition(field.(*Field)); err != nil {
+			return err
+		}
+	}
+	return c.writeEndPacket(capabilities, c.StatusFlags|mysql.ServerStatusCursorExists, 0, 0, warnings)
+}
+
 func (c *Conn) writeRow(row []*proto.Value) error {
 	length := 0
 	for _, val := range row {
@@ -1056,12 +1493,17 @@ func (c *Conn) writeRows(result proto.Result) error {
 // writeEndResult concludes the sending of a Result.
 // if more is set to true, then it means there are more results afterwords
 func (c *Conn) writeEndResult(capabilities uint32, more bool, affectedRows, lastInsertID uint64, warnings uint16) error {
-	// Send either an EOF, or an OK packet.
-	// See doc.go.
 	flags := c.StatusFlags
 	if more {
 		flags |= mysql.ServerMoreResultsExists
 	}
+	return c.writeEndPacket(capabilities, flags, affectedRows, lastInsertID, warnings)
+}
+
+// writeEndPacket writes the end of result with the status flags.
+func (c *Conn) writeEndPacket(capabilities uint32, flags uint16, affectedRows, lastInsertID uint64, warnings uint16) error {
+	// Send either an EOF, or an OK packet.
+	// See doc.go.
 	if capabilities&mysql.CapabilityClientDeprecateEOF == 0 {
 		if err := c.writeEOFPacket(flags, warnings); err != nil {
 			return err
@@ -1550,8 +1992,11 @@ func val2MySQLLen(v *proto.Value) (int, error) {
 }
 
 func (c *Conn) writeBinaryRows(result proto.Result) error {
-	rlt := result.(*Result)
-	for _, row := range rlt.Rows {
+	return c.writeBinaryRowsOf(result.(*Result).Rows)
+}
+
+func (c *Conn) writeBinaryRowsOf(rows []proto.Row) error {
+	for _, row := range rows {
 		r := row.(*Row)
 		if err := c.writePacket(r.Data()); err != nil {
 			return err
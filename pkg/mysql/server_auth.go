@@ -0,0 +1,371 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/util/log"
+)
+
+const (
+	_rsaKeyBits                    = 2048
+	sha256PasswordRequestPublicKey = 1
+)
+
+// _sha2Cache caches SHA256(SHA256(password)) for the fast authentication of caching_sha2_password,
+// only the users whose password is stored as mysql_native_password hash are cached, since the hash cannot be
+// derived from the stored password. The entries are dropped by ForgetSha2Hash once the users are changed or removed.
+var _sha2Cache sync.Map // sha2CacheKey -> *sha2CacheEntry
+
+type (
+	sha2CacheKey struct {
+		tenant   string
+		username string
+	}
+
+	sha2CacheEntry struct {
+		// stored is the mysql_native_password hash which the cached hash is verified against.
+		stored string
+		hash   []byte
+	}
+)
+
+// ForgetSha2Hash drops the cached caching_sha2_password hash of user, or of all users of tenant if username is empty.
+func ForgetSha2Hash(tenant, username string) {
+	if len(username) > 0 {
+		_sha2Cache.Delete(sha2CacheKey{tenant: tenant, username: username})
+		return
+	}
+	_sha2Cache.Range(func(key, _ interface{}) bool {
+		if key.(sha2CacheKey).tenant == tenant {
+			_sha2Cache.Delete(key)
+		}
+		return true
+	})
+}
+
+// newSha2CacheKey returns the cache key of the user to be authenticated.
+func newSha2CacheKey(handshake *handshakeResult) sha2CacheKey {
+	tenant, _ := security.DefaultTenantManager().GetTenantOfCluster(handshake.schema)
+	return sha2CacheKey{tenant: tenant, username: handshake.username}
+}
+
+// authenticate authenticates the client by its auth method,
+// the client will be asked to switch to the default auth method if its auth method is not supported.
+func (l *Listener) authenticate(c *Conn, handshake *handshakeResult) error {
+	switch handshake.authMethod {
+	case mysql.MysqlNativePassword, mysql.CachingSha2Password, mysql.Sha256Password:
+	default:
+		if err := writeAuthSwitchRequest(c, l.conf.DefaultAuthPlugin, handshake.salt); err != nil {
+			return err
+		}
+		data, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		handshake.authMethod = l.conf.DefaultAuthPlugin
+		handshake.authResponse = data
+	}
+
+	var cred security.Credential
+	switch handshake.authMethod {
+	case mysql.CachingSha2Password:
+		cred = &sha2Credential{key: newSha2CacheKey(handshake), salt: handshake.salt, authResponse: handshake.authResponse}
+	case mysql.Sha256Password:
+		password, err := l.readClearPassword(c, handshake, handshake.authResponse, sha256PasswordRequestPublicKey)
+		if err != nil {
+			return err
+		}
+		cred = &clearCredential{password: password}
+	default:
+		cred = &scrambleCredential{salt: handshake.salt, authResponse: handshake.authResponse}
+	}
+
+	err := l.verify(handshake, cred)
+	if err == nil {
+		if _, ok := cred.(*sha2Credential); ok {
+			return c.writePacket([]byte{mysql.AuthMoreDataPacket, cachingSha2PasswordFastAuthSuccess})
+		}
+		return nil
+	}
+
+	// the credential cannot be verified by scramble, ask the client to send the clear password
+	var data []byte
+	switch it := cred.(type) {
+	case *sha2Credential:
+		if !it.clearRequired {
+			return err
+		}
+		// perform the full authentication, which requires the clear password over TLS or encrypted by RSA
+		if err = c.writePacket([]byte{mysql.AuthMoreDataPacket, cachingSha2PasswordPerformFullAuthentication}); err != nil {
+			return err
+		}
+		if data, err = c.readPacket(); err != nil {
+			return err
+		}
+		password, err := l.readClearPassword(c, handshake, data, cachingSha2PasswordRequestPublicKey)
+		if err != nil {
+			return err
+		}
+		clear := &clearCredential{password: password}
+		if err = l.verify(handshake, clear); err != nil {
+			return err
+		}
+		clear.cacheSha2Hash(it.key)
+		return nil
+	case *scrambleCredential:
+		if !it.clearRequired {
+			return err
+		}
+		// never ask for the clear password in plaintext, caching_sha2_password or sha256_password should be used instead
+		if !handshake.secure {
+			log.Warnf("refuse to switch to %s for user %s without TLS", mysql.MysqlClearPassword, handshake.username)
+			return err
+		}
+		if err = writeAuthSwitchRequest(c, mysql.MysqlClearPassword, nil); err != nil {
+			return err
+		}
+		if data, err = c.readPacket(); err != nil {
+			return err
+		}
+		handshake.authMethod = mysql.MysqlClearPassword
+		return l.verify(handshake, &clearCredential{password: string(bytes.TrimSuffix(data, []byte{0}))})
+	default:
+		return err
+	}
+}
+
+// scrambleCredential is the credential of mysql_native_password.
+type scrambleCredential struct {
+	salt          []byte
+	authResponse  []byte
+	clearRequired bool
+}
+
+func (s *scrambleCredential) Verify(stored string) bool {
+	if isNativePasswordHash(stored) {
+		return checkNativePasswordHash(s.salt, s.authResponse, stored)
+	}
+	return bytes.Equal(s.authResponse, scramblePassword(s.salt, stored))
+}
+
+func (s *scrambleCredential) ClearPassword() (string, bool) {
+	s.clearRequired = true
+	return "", false
+}
+
+// sha2Credential is the credential of caching_sha2_password fast authentication.
+type sha2Credential struct {
+	key           sha2CacheKey
+	salt          []byte
+	authResponse  []byte
+	clearRequired bool
+}
+
+func (s *sha2Credential) Verify(stored string) bool {
+	if len(s.authResponse) == 0 {
+		return len(stored) == 0
+	}
+	hash, ok := getSha2Hash(s.key, stored)
+	if !ok {
+		// perform the full authentication if the hash is not cached
+		s.clearRequired = true
+		return false
+	}
+	return checkSha2Scramble(s.salt, s.authResponse, hash)
+}
+
+func (s *sha2Credential) ClearPassword() (string, bool) {
+	s.clearRequired = true
+	return "", false
+}
+
+// clearCredential is the credential of clear password.
+type clearCredential struct {
+	password string
+	verified []string
+}
+
+func (c *clearCredential) Verify(stored string) bool {
+	if !checkClearPassword(c.password, stored) {
+		return false
+	}
+	c.verified = append(c.verified, stored)
+	return true
+}
+
+func (c *clearCredential) ClearPassword() (string, bool) {
+	return c.password, true
+}
+
+// cacheSha2Hash caches the hash of verified password for the fast authentication of caching_sha2_password.
+func (c *clearCredential) cacheSha2Hash(key sha2CacheKey) {
+	for _, it := range c.verified {
+		if isNativePasswordHash(it) {
+			_sha2Cache.Store(key, &sha2CacheEntry{stored: it, hash: sha2Hash(c.password)})
+		}
+	}
+}
+
+// readClearPassword returns the clear password from auth data, which is sent as cleartext over TLS,
+// or encrypted by the RSA public key, the public key will be sent if the client requests it.
+func (l *Listener) readClearPassword(c *Conn, handshake *handshakeResult, data []byte, requestPublicKey byte) (string, error) {
+	if handshake.secure || len(data) == 0 || (len(data) == 1 && data[0] == 0) {
+		return string(bytes.TrimSuffix(data, []byte{0})), nil
+	}
+
+	key, err := l.getRSAKey()
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) == 1 && data[0] == requestPublicKey {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		if err = c.writePacket(append([]byte{mysql.AuthMoreDataPacket}, pub...)); err != nil {
+			return "", err
+		}
+		if data, err = c.readPacket(); err != nil {
+			return "", err
+		}
+	}
+
+	plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, data, nil)
+	if err != nil {
+		return "", errAccessDenied(handshake)
+	}
+	for i := range plain {
+		plain[i] ^= handshake.salt[i%len(handshake.salt)]
+	}
+	return string(bytes.TrimSuffix(plain, []byte{0})), nil
+}
+
+func (l *Listener) getRSAKey() (*rsa.PrivateKey, error) {
+	l.rsaKeyOnce.Do(func() {
+		if l.rsaKey == nil {
+			l.rsaKey, l.rsaKeyErr = rsa.GenerateKey(rand.Reader, _rsaKeyBits)
+		}
+	})
+	return l.rsaKey, l.rsaKeyErr
+}
+
+// loadRSAPrivateKey loads the PEM encoded RSA private key in PKCS#1 or PKCS#8.
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rsa private key")
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.Errorf("no pem data found in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse rsa private key %s", path)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("%s is not a rsa private key", path)
+	}
+	return rsaKey, nil
+}
+
+// writeAuthSwitchRequest asks the client to switch to another auth method.
+func writeAuthSwitchRequest(c *Conn, method string, salt []byte) error {
+	data := make([]byte, 0, 1+len(method)+1+len(salt)+1)
+	data = append(data, mysql.AuthSwitchRequestPacket)
+	data = append(data, method...)
+	data = append(data, 0)
+	data = append(data, salt...)
+	data = append(data, 0)
+	return c.writePacket(data)
+}
+
+// getSha2Hash computes SHA256(SHA256(password)) if the password is stored in plaintext,
+// or returns it from cache if the stored mysql_native_password hash is not changed.
+func getSha2Hash(key sha2CacheKey, stored string) ([]byte, bool) {
+	if !isNativePasswordHash(stored) {
+		return sha2Hash(stored), true
+	}
+	if v, ok := _sha2Cache.Load(key); ok {
+		if entry := v.(*sha2CacheEntry); entry.stored == stored {
+			return entry.hash, true
+		}
+	}
+	return nil, false
+}
+
+// sha2Hash returns SHA256(SHA256(password)).
+func sha2Hash(password string) []byte {
+	stage1 := sha256.Sum256([]byte(password))
+	stage2 := sha256.Sum256(stage1[:])
+	return stage2[:]
+}
+
+// checkSha2Scramble checks the caching_sha2_password scramble against SHA256(SHA256(password)).
+func checkSha2Scramble(scramble, authResponse, hash []byte) bool {
+	if len(authResponse) != sha256.Size {
+		return false
+	}
+
+	// stage1Hash = authResponse XOR SHA256(stage2Hash + scramble)
+	crypt := sha256.New()
+	crypt.Write(hash)
+	crypt.Write(scramble)
+	stage1 := crypt.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= authResponse[i]
+	}
+
+	// check SHA256(stage1Hash) == stage2Hash
+	stage2 := sha256.Sum256(stage1)
+	return subtle.ConstantTimeCompare(stage2[:], hash) == 1
+}
+
+// checkClearPassword checks the clear password against the stored password, which may be a mysql_native_password hash.
+func checkClearPassword(password, stored string) bool {
+	if isNativePasswordHash(stored) {
+		return strings.EqualFold(NativePasswordHash(password), stored)
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(stored)) == 1
+}
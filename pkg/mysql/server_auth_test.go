@@ -0,0 +1,321 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+func TestAuthPlugins(t *testing.T) {
+	const (
+		tenant  = "fake_auth_plugin_tenant"
+		cluster = "fake_auth_plugin_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "plain", Password: "123456"})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "hashed", Password: NativePasswordHash("123456")})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "empty"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	var (
+		dir    = t.TempDir()
+		ca     = newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+		server = newTestCert(t, pkix.Name{CommonName: "server"}, ca)
+	)
+	certFile, keyFile := server.writeTo(t, dir, "server")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	assert.NoError(t, driver.RegisterTLSConfig("arana-auth-test", &tls.Config{RootCAs: roots, ServerName: "localhost"}))
+
+	for _, plugin := range []string{mysql.MysqlNativePassword, mysql.CachingSha2Password, mysql.Sha256Password} {
+		pl, err := NewListener(&config.Listener{
+			SocketAddress:     &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+			ServerVersion:     "8.0.0",
+			TLS:               &config.TLS{CertFile: certFile, KeyFile: keyFile},
+			DefaultAuthPlugin: plugin,
+		})
+		assert.NoError(t, err)
+		l := pl.(*Listener)
+		go serveHandshake(l)
+
+		for _, tlsConfig := range []string{"false", "arana-auth-test"} {
+			for _, user := range []string{"plain", "hashed"} {
+				assert.NoError(t, testConnect(l, cluster, user, "123456", tlsConfig), "%s %s %s", plugin, user, tlsConfig)
+				assertAccessDenied(t, testConnect(l, cluster, user, "654321", tlsConfig))
+			}
+			assert.NoError(t, testConnect(l, cluster, "empty", "", tlsConfig), "%s %s", plugin, tlsConfig)
+			assertAccessDenied(t, testConnect(l, cluster, "empty", "123456", tlsConfig))
+		}
+
+		_ = l.listener.Close()
+	}
+
+	// the hashed password is cached after the full authentication
+	_, ok := _sha2Cache.Load(sha2CacheKey{tenant: tenant, username: "hashed"})
+	assert.True(t, ok)
+	ForgetSha2Hash(tenant, "")
+
+	_, err := NewListener(&config.Listener{
+		SocketAddress:     &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		DefaultAuthPlugin: "dialog",
+	})
+	assert.Error(t, err)
+}
+
+func TestAuthSwitch(t *testing.T) {
+	const (
+		tenant  = "fake_auth_switch_tenant"
+		cluster = "fake_auth_switch_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	salt, err := newSalt()
+	assert.NoError(t, err)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	l := &Listener{conf: &ServerConfig{DefaultAuthPlugin: mysql.MysqlNativePassword}}
+	done := make(chan error, 1)
+	go func() {
+		defer server.Close()
+		done <- l.authenticate(newConn(server), &handshakeResult{
+			schema:     cluster,
+			username:   "foo",
+			authMethod: mysql.MysqlClearPassword,
+			salt:       salt,
+		})
+	}()
+
+	c := newConn(client)
+	data, err := c.readPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(mysql.AuthSwitchRequestPacket), data[0])
+	assert.Equal(t, append(append([]byte(mysql.MysqlNativePassword), 0), append(salt, 0)...), data[1:])
+	assert.NoError(t, c.writePacket(scramblePassword(salt, "123456")))
+	assert.NoError(t, <-done)
+}
+
+func TestAuthenticator(t *testing.T) {
+	const (
+		tenant  = "fake_authenticator_tenant"
+		cluster = "fake_authenticator_cluster"
+	)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req["password"] {
+		case "token":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"allow": true})
+		case "other":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"allow": true, "tenant": "other_tenant"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"allow": false})
+		}
+	}))
+	defer callback.Close()
+
+	auth, err := security.NewAuthenticator(&config.Authenticator{
+		Type:    security.HttpAuthenticator,
+		Options: map[string]string{"url": callback.URL},
+	})
+	assert.NoError(t, err)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().SetAuthenticator(tenant, auth)
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+	defer security.DefaultTenantManager().SetAuthenticator(tenant, nil)
+
+	connect := func(l *Listener, password, tlsConfig string) error {
+		cfg := driver.NewConfig()
+		cfg.User, cfg.Passwd, cfg.DBName = "foo", password, cluster
+		cfg.Net, cfg.Addr = "tcp", l.listener.Addr().String()
+		cfg.TLSConfig = tlsConfig
+		cfg.AllowCleartextPasswords = true
+		connector, err := driver.NewConnector(cfg)
+		if err != nil {
+			return err
+		}
+		conn, err := connector.Connect(context.Background())
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	for _, plugin := range []string{mysql.MysqlNativePassword, mysql.CachingSha2Password, mysql.Sha256Password} {
+		pl, err := NewListener(&config.Listener{
+			SocketAddress:     &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+			ServerVersion:     "8.0.0",
+			DefaultAuthPlugin: plugin,
+		})
+		assert.NoError(t, err)
+		l := pl.(*Listener)
+		go serveHandshake(l)
+
+		if plugin == mysql.MysqlNativePassword {
+			// the clear password is never asked in plaintext
+			assertAccessDenied(t, connect(l, "token", "false"))
+			_ = l.listener.Close()
+			continue
+		}
+
+		// the clear password is required by the http authenticator
+		assert.NoError(t, connect(l, "token", "false"), plugin)
+		assertAccessDenied(t, connect(l, "wrong", "false"))
+		// the bound tenant doesn't own the schema
+		assertAccessDenied(t, connect(l, "other", "false"))
+
+		_ = l.listener.Close()
+	}
+
+	// switch to mysql_clear_password over TLS
+	var (
+		dir    = t.TempDir()
+		ca     = newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+		server = newTestCert(t, pkix.Name{CommonName: "server"}, ca)
+	)
+	certFile, keyFile := server.writeTo(t, dir, "server")
+	pl, err := NewListener(&config.Listener{
+		SocketAddress:     &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion:     "8.0.0",
+		DefaultAuthPlugin: mysql.MysqlNativePassword,
+		TLS:               &config.TLS{CertFile: certFile, KeyFile: keyFile},
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	defer l.listener.Close()
+	go serveHandshake(l)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	assert.NoError(t, driver.RegisterTLSConfig("arana-test-auth", &tls.Config{RootCAs: roots, ServerName: "localhost"}))
+	defer driver.DeregisterTLSConfig("arana-test-auth")
+
+	assert.NoError(t, connect(l, "token", "arana-test-auth"))
+	assertAccessDenied(t, connect(l, "wrong", "arana-test-auth"))
+	assertAccessDenied(t, connect(l, "token", "false"))
+}
+
+func TestLoadRSAPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	for name, block := range map[string]*pem.Block{
+		"pkcs1.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		"pkcs8.pem": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+		loaded, err := loadRSAPrivateKey(path)
+		assert.NoError(t, err)
+		assert.True(t, key.Equal(loaded))
+	}
+
+	path := filepath.Join(dir, "invalid.pem")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("invalid"), 0o600))
+	_, err = loadRSAPrivateKey(path)
+	assert.Error(t, err)
+}
+
+func TestCheckClearPassword(t *testing.T) {
+	assert.True(t, checkClearPassword("123456", "123456"))
+	assert.True(t, checkClearPassword("123456", NativePasswordHash("123456")))
+	assert.False(t, checkClearPassword("654321", "123456"))
+	assert.False(t, checkClearPassword("654321", NativePasswordHash("123456")))
+
+	salt, err := newSalt()
+	assert.NoError(t, err)
+	key := sha2CacheKey{tenant: "fake_tenant", username: "foo"}
+	hash, ok := getSha2Hash(key, "123456")
+	assert.True(t, ok)
+	assert.True(t, checkSha2Scramble(salt, scrambleSHA256Password(salt, "123456"), hash))
+	assert.False(t, checkSha2Scramble(salt, scrambleSHA256Password(salt, "654321"), hash))
+	_, ok = getSha2Hash(key, NativePasswordHash("654321"))
+	assert.False(t, ok)
+}
+
+func TestSha2Cache(t *testing.T) {
+	var (
+		foo = sha2CacheKey{tenant: "fake_tenant", username: "foo"}
+		bar = sha2CacheKey{tenant: "fake_tenant", username: "bar"}
+	)
+	for _, key := range []sha2CacheKey{foo, bar} {
+		clear := &clearCredential{password: "123456"}
+		assert.True(t, clear.Verify(NativePasswordHash("123456")))
+		clear.cacheSha2Hash(key)
+	}
+
+	// only the digest is cached
+	v, ok := _sha2Cache.Load(foo)
+	if assert.True(t, ok) {
+		assert.Equal(t, &sha2CacheEntry{stored: NativePasswordHash("123456"), hash: sha2Hash("123456")}, v)
+	}
+	hash, ok := getSha2Hash(foo, NativePasswordHash("123456"))
+	assert.True(t, ok)
+	assert.Equal(t, sha2Hash("123456"), hash)
+
+	// the entry is missed once the password is changed
+	_, ok = getSha2Hash(foo, NativePasswordHash("654321"))
+	assert.False(t, ok)
+
+	// the plaintext password is never cached
+	clear := &clearCredential{password: "123456"}
+	assert.True(t, clear.Verify("123456"))
+	clear.cacheSha2Hash(sha2CacheKey{tenant: "fake_tenant", username: "plain"})
+	_, ok = _sha2Cache.Load(sha2CacheKey{tenant: "fake_tenant", username: "plain"})
+	assert.False(t, ok)
+
+	ForgetSha2Hash("fake_tenant", "foo")
+	_, ok = _sha2Cache.Load(foo)
+	assert.False(t, ok)
+	_, ok = _sha2Cache.Load(bar)
+	assert.True(t, ok)
+	ForgetSha2Hash("fake_tenant", "")
+	_, ok = _sha2Cache.Load(bar)
+	assert.False(t, ok)
+}
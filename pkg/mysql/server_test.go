@@ -0,0 +1,372 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+// startShutdownListener starts a listener with fake executor, and opens a db to it.
+func startShutdownListener(t *testing.T, executor *fakeQueryExecutor) (*Listener, *sql.DB) {
+	const (
+		tenant  = "fake_shutdown_tenant"
+		cluster = "fake_shutdown_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	t.Cleanup(func() {
+		security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+	})
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	l.SetExecutor(executor)
+	go l.Listen()
+
+	cfg := driver.NewConfig()
+	cfg.User, cfg.Passwd, cfg.DBName = "foo", "123456", cluster
+	cfg.Net, cfg.Addr = "tcp", l.listener.Addr().String()
+	connector, err := driver.NewConnector(cfg)
+	assert.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return l, db
+}
+
+func TestShutdown(t *testing.T) {
+	executor := &fakeQueryExecutor{wait: make(chan struct{})}
+	l, db := startShutdownListener(t, executor)
+
+	ctx := context.Background()
+	idle, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer idle.Close()
+	busy, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer busy.Close()
+	assert.NoError(t, idle.PingContext(ctx))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := busy.ExecContext(ctx, "update t set wait = 1")
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		executor.mu.Lock()
+		defer executor.mu.Unlock()
+		return len(executor.executed) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	shutdown := make(chan struct{})
+	go func() {
+		l.Shutdown(ctx)
+		close(shutdown)
+	}()
+
+	// the idle connections are closed, and the new connections are refused
+	assert.Eventually(t, func() bool {
+		return idle.PingContext(ctx) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		c, err := net.Dial("tcp", l.listener.Addr().String())
+		if err == nil {
+			_ = c.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the in-flight statement is finished before shutdown
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown before the in-flight statement is finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(executor.wait)
+	assert.NoError(t, <-done)
+
+	select {
+	case <-shutdown:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is blocked")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	executor := &fakeQueryExecutor{}
+	l, db := startShutdownListener(t, executor)
+
+	ctx := context.Background()
+	busy, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer busy.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := busy.ExecContext(ctx, "select sleep(100)")
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		executor.mu.Lock()
+		defer executor.mu.Unlock()
+		return len(executor.executed) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the statements are killed if they are not finished in time
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	l.Shutdown(timeout)
+
+	select {
+	case err = <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the statement is not killed")
+	}
+}
+
+func TestChangeUserAndResetConnection(t *testing.T) {
+	const (
+		tenant  = "fake_change_user_tenant"
+		cluster = "fake_change_user_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "bar", Password: "654321"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	executor := &fakeQueryExecutor{}
+	l.SetExecutor(executor)
+	go l.Listen()
+	defer l.Close()
+
+	connector, err := NewConnector([]byte(fmt.Sprintf(`{"dsn": "foo:123456@tcp(%s)/%s"}`, l.listener.Addr(), cluster)))
+	assert.NoError(t, err)
+	res, err := connector.NewBackendConnection(context.Background())
+	assert.NoError(t, err)
+	bc := res.(*BackendConnection)
+	defer bc.Close()
+
+	// writeCommand writes a command, and returns the error of response
+	writeCommand := func(data []byte) error {
+		bc.c.sequence = 0
+		if err := bc.c.writePacket(data); err != nil {
+			return err
+		}
+		response, err := bc.c.readPacket()
+		if err != nil {
+			return err
+		}
+		if isErrorPacket(response) {
+			return ParseErrorPacket(response)
+		}
+		return nil
+	}
+	// changeUser builds the COM_CHANGE_USER packet
+	changeUser := func(username, password string) []byte {
+		sc, _ := l.conns.Load(bc.c.ConnectionID)
+		scramble := scramblePassword(sc.(*Conn).salt, password)
+		data := append([]byte{consts.ComChangeUser}, username...)
+		data = append(data, 0, byte(len(scramble)))
+		data = append(data, scramble...)
+		data = append(data, cluster...)
+		data = append(data, 0, consts.CharacterSetUtf8, 0)
+		data = append(data, consts.MysqlNativePassword...)
+		return append(data, 0)
+	}
+	// countStmts returns the amount of prepared statements
+	countStmts := func() (n int) {
+		l.stmts.Range(func(_, _ interface{}) bool {
+			n++
+			return true
+		})
+		return
+	}
+
+	// the transaction is rolled back, and the prepared statements are closed
+	_, err = bc.prepare("update t set a = ?")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countStmts())
+	assert.NoError(t, writeCommand([]byte{consts.ComResetConnection}))
+	assert.Equal(t, 0, countStmts())
+	executor.mu.Lock()
+	assert.Equal(t, 1, executor.closed)
+	executor.mu.Unlock()
+
+	// the connection is reset and re-authenticated as the new user
+	_, err = bc.prepare("update t set a = ?")
+	assert.NoError(t, err)
+	assert.NoError(t, writeCommand(changeUser("bar", "654321")))
+	assert.Equal(t, 0, countStmts())
+	result, err := bc.Execute("SHOW PROCESSLIST", true)
+	if assert.NoError(t, err) && assert.Len(t, result.GetRows(), 1) {
+		row := result.GetRows()[0].(*Row)
+		values, err := (&TextRow{*row}).Decode()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("bar"), values[1].Val)
+	}
+
+	// the connection is closed if failed to change user
+	err = writeCommand(changeUser("foo", "wrong"))
+	if assert.IsType(t, (*errors.SQLError)(nil), err) {
+		assert.Equal(t, consts.ERAccessDeniedError, err.(*errors.SQLError).Num)
+	}
+	_, err = bc.c.readPacket()
+	assert.Error(t, err)
+}
+
+func TestStmtSendLongData(t *testing.T) {
+	const (
+		tenant  = "fake_long_data_tenant"
+		cluster = "fake_long_data_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	executor := &fakeQueryExecutor{}
+	l.SetExecutor(executor)
+	go l.Listen()
+	defer l.Close()
+
+	// the args larger than 1024/3 bytes are sent by COM_STMT_SEND_LONG_DATA in chunks
+	dsn := fmt.Sprintf(`{"dsn": "foo:123456@tcp(%s)/%s?maxAllowedPacket=1024"}`, l.listener.Addr(), cluster)
+	connector, err := NewConnector([]byte(dsn))
+	assert.NoError(t, err)
+	res, err := connector.NewBackendConnection(context.Background())
+	assert.NoError(t, err)
+	bc := res.(*BackendConnection)
+	defer bc.Close()
+
+	stmt, err := bc.prepare("insert into t values (?, ?)")
+	assert.NoError(t, err)
+	bindVars := func() map[string]interface{} {
+		executor.mu.Lock()
+		defer executor.mu.Unlock()
+		return executor.bindVars
+	}
+
+	blob := []byte(strings.Repeat("0123456789", 150))
+	_, _, err = stmt.queryArgs([]interface{}{int64(1), blob})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(1), "v2": blob}, bindVars())
+
+	// the long data is cleared after execution
+	_, _, err = stmt.queryArgs([]interface{}{int64(2), []byte("small")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(2), "v2": []byte("small")}, bindVars())
+
+	// the long data is cleared by COM_STMT_RESET
+	reset := func(stmt *BackendStatement) error {
+		data := []byte{consts.ComStmtReset, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(data[1:], stmt.id)
+		stmt.conn.c.sequence = 0
+		if err := stmt.conn.c.writePacket(data); err != nil {
+			return err
+		}
+		response, err := stmt.conn.c.readPacket()
+		if err != nil {
+			return err
+		}
+		if isErrorPacket(response) {
+			return ParseErrorPacket(response)
+		}
+		return nil
+	}
+	assert.NoError(t, stmt.writeCommandLongData(1, []byte("dropped")))
+	assert.NoError(t, reset(stmt))
+	_, _, err = stmt.queryArgs([]interface{}{int64(3), []byte("small")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(3), "v2": []byte("small")}, bindVars())
+
+	// the long data of unknown parameter is ignored
+	assert.NoError(t, stmt.writeCommandLongData(2, []byte("dropped")))
+	_, _, err = stmt.queryArgs([]interface{}{int64(4), []byte("small")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(4), "v2": []byte("small")}, bindVars())
+
+	// the statement cannot be used by other connection
+	res, err = connector.NewBackendConnection(context.Background())
+	assert.NoError(t, err)
+	other := res.(*BackendConnection)
+	defer other.Close()
+	stolen := &BackendStatement{conn: other, id: stmt.id, paramCount: stmt.paramCount}
+	assert.NoError(t, stolen.writeCommandLongData(1, []byte("stolen")))
+	err = reset(stolen)
+	if assert.IsType(t, (*errors.SQLError)(nil), err) {
+		assert.Equal(t, consts.ERUnknownStmtHandler, err.(*errors.SQLError).Num)
+	}
+	_, _, err = stolen.queryArgs([]interface{}{int64(5), []byte("stolen")})
+	if assert.IsType(t, (*errors.SQLError)(nil), err) {
+		assert.Equal(t, consts.CRCommandsOutOfSync, err.(*errors.SQLError).Num)
+	}
+	_, _, err = stmt.queryArgs([]interface{}{int64(6), []byte("small")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(6), "v2": []byte("small")}, bindVars())
+
+	// the statement cannot be closed by other connection, COM_STMT_CLOSE has no response
+	data := []byte{consts.ComStmtClose, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(data[1:], stmt.id)
+	other.c.sequence = 0
+	assert.NoError(t, other.c.writePacket(data))
+	// the response of COM_PING makes sure COM_STMT_CLOSE is handled
+	other.c.sequence = 0
+	assert.NoError(t, other.c.writePacket([]byte{consts.ComPing}))
+	_, err = other.c.readPacket()
+	assert.NoError(t, err)
+	_, _, err = stmt.queryArgs([]interface{}{int64(7), []byte("small")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"v1": int64(7), "v2": []byte("small")}, bindVars())
+}
@@ -0,0 +1,153 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// _charsetVariables are the variables affected by 'SET NAMES' and 'SET CHARACTER SET',
+// which depend on each other, so they are always replayed together in order.
+var _charsetVariables = map[string]struct{}{
+	proto.VarNames:               {},
+	proto.VarCharset:             {},
+	"@@character_set_client":     {},
+	"@@character_set_results":    {},
+	"@@character_set_connection": {},
+	"@@collation_connection":     {},
+}
+
+// SyncVariables replays the session variables on the connection,
+// and resets the variables applied by previous sessions but not in vars.
+func (conn *BackendConnection) SyncVariables(vars []proto.Variable) error {
+	query := conn.syncVariablesQuery(vars)
+	if len(query) < 1 {
+		return nil
+	}
+	if _, err := conn.Execute(query, false); err != nil {
+		return err
+	}
+	conn.variables = append([]proto.Variable(nil), vars...)
+	return nil
+}
+
+// syncVariablesQuery returns the SET statement to sync variables, returns empty if no changes.
+func (conn *BackendConnection) syncVariablesQuery(vars []proto.Variable) string {
+	var (
+		applied                    = make(map[string]string, len(conn.variables))
+		current                    = make(map[string]struct{}, len(vars))
+		appliedCharset, curCharset []proto.Variable
+		assigns                    []string
+	)
+	for _, it := range conn.variables {
+		if _, ok := _charsetVariables[it.Name]; ok {
+			appliedCharset = append(appliedCharset, it)
+		} else {
+			applied[it.Name] = it.Value
+		}
+	}
+	for _, it := range vars {
+		if _, ok := _charsetVariables[it.Name]; ok {
+			curCharset = append(curCharset, it)
+		} else {
+			current[it.Name] = struct{}{}
+		}
+	}
+
+	for _, it := range conn.variables {
+		if _, ok := _charsetVariables[it.Name]; ok {
+			continue
+		}
+		if _, ok := current[it.Name]; !ok {
+			assigns = append(assigns, resetVariable(it.Name))
+		}
+	}
+
+	if !equalVariables(appliedCharset, curCharset) {
+		// restore the charset of handshake before replaying
+		if len(appliedCharset) > 0 {
+			assigns = append(assigns, conn.defaultNames())
+		}
+		for _, it := range curCharset {
+			assigns = append(assigns, assignVariable(it))
+		}
+	}
+
+	for _, it := range vars {
+		if _, ok := _charsetVariables[it.Name]; ok {
+			continue
+		}
+		if v, ok := applied[it.Name]; !ok || v != it.Value {
+			assigns = append(assigns, assignVariable(it))
+		}
+	}
+
+	if len(assigns) < 1 {
+		return ""
+	}
+	return "SET " + strings.Join(assigns, ", ")
+}
+
+// defaultNames returns the 'NAMES' assignment of the collation used by handshake.
+func (conn *BackendConnection) defaultNames() string {
+	collation := conn.conf.Collation
+	charset := collation
+	if i := strings.IndexByte(collation, '_'); i > 0 {
+		charset = collation[:i]
+	}
+	return fmt.Sprintf("NAMES '%s' COLLATE '%s'", charset, collation)
+}
+
+func assignVariable(v proto.Variable) string {
+	switch {
+	case v.Name == proto.VarNames || v.Name == proto.VarCharset:
+		return v.Name + " " + v.Value
+	case strings.HasPrefix(v.Name, "@@"):
+		return "@@SESSION." + v.Name[2:] + " = " + v.Value
+	default:
+		return quoteUserVariable(v.Name) + " = " + v.Value
+	}
+}
+
+func resetVariable(name string) string {
+	if strings.HasPrefix(name, "@@") {
+		return "@@SESSION." + name[2:] + " = DEFAULT"
+	}
+	return quoteUserVariable(name) + " = NULL"
+}
+
+func quoteUserVariable(name string) string {
+	return "@`" + strings.ReplaceAll(strings.TrimPrefix(name, "@"), "`", "``") + "`"
+}
+
+func equalVariables(a, b []proto.Variable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+func TestSyncVariablesQuery(t *testing.T) {
+	conn := &BackendConnection{conf: NewConfig()}
+
+	var (
+		names   = proto.Variable{Name: proto.VarNames, Value: "'utf8mb4' COLLATE 'utf8mb4_bin'"}
+		results = proto.Variable{Name: "@@character_set_results", Value: "NULL"}
+		sqlMode = proto.Variable{Name: "@@sql_mode", Value: "''"}
+		userVar = proto.Variable{Name: "@a`b", Value: "1"}
+	)
+
+	// nothing to sync
+	assert.Empty(t, conn.syncVariablesQuery(nil))
+
+	vars := []proto.Variable{names, results, sqlMode, userVar}
+	assert.Equal(t,
+		"SET NAMES 'utf8mb4' COLLATE 'utf8mb4_bin', @@SESSION.character_set_results = NULL, @@SESSION.sql_mode = '', @`a``b` = 1",
+		conn.syncVariablesQuery(vars))
+	conn.variables = vars
+	assert.Empty(t, conn.syncVariablesQuery(vars))
+
+	// only the changed variables are replayed, and the removed ones are reset
+	assert.Equal(t,
+		"SET @`a``b` = NULL, @@SESSION.sql_mode = 'ANSI'",
+		conn.syncVariablesQuery([]proto.Variable{names, results, {Name: "@@sql_mode", Value: "'ANSI'"}}))
+
+	// the charset variables are replayed together after restoring the default charset
+	assert.Equal(t,
+		"SET NAMES 'utf8mb4' COLLATE 'utf8mb4_general_ci', NAMES 'utf8mb4' COLLATE 'utf8mb4_bin'",
+		conn.syncVariablesQuery([]proto.Variable{names, sqlMode, userVar}))
+	assert.Equal(t,
+		"SET @@SESSION.sql_mode = DEFAULT, @`a``b` = NULL, NAMES 'utf8mb4' COLLATE 'utf8mb4_general_ci'",
+		conn.syncVariablesQuery(nil))
+}
@@ -104,8 +104,8 @@ func (stmt *BackendStatement) writeCommandLongData(paramID int, arg []byte) erro
 		data[9] = byte(paramID)
 		data[10] = byte(paramID >> 8)
 
-		// Send CMD packet
-		err := stmt.conn.c.writePacket(data[:4+pktLen])
+		// Send CMD packet, the header is written by writePacket
+		err := stmt.conn.c.writePacket(data[4 : 4+pktLen])
 		if err == nil {
 			data = data[pktLen-dataOffset:]
 			continue
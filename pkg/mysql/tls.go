@@ -0,0 +1,67 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+)
+
+// newServerTLSConfig creates the TLS config of listener.
+func newServerTLSConfig(conf *config.TLS) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load server certificate")
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(conf.CAFile) < 1 {
+		if conf.RequireClientCert {
+			return nil, errors.New("no ca file specified to verify client certificates")
+		}
+		return c, nil
+	}
+
+	ca, err := ioutil.ReadFile(conf.CAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ca file")
+	}
+	c.ClientCAs = x509.NewCertPool()
+	if !c.ClientCAs.AppendCertsFromPEM(ca) {
+		return nil, errors.Errorf("no valid certificates found in ca file %s", conf.CAFile)
+	}
+
+	c.ClientAuth = tls.VerifyClientCertIfGiven
+	if conf.RequireClientCert {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return c, nil
+}
@@ -0,0 +1,218 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mysql
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/security"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCert(t *testing.T, subject pkix.Name, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (c *testCert) writeTo(t *testing.T, dir, name string) (certFile, keyFile string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	assert.NoError(t, ioutil.WriteFile(certFile, c.pem, 0o600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+func TestListenerTLS(t *testing.T) {
+	const (
+		tenant  = "fake_tls_tenant"
+		cluster = "fake_tls_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "plain", Password: "123456"})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "secure", Password: "123456", RequireTLS: true})
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "cert", Password: "123456", CertSubject: "CN=client,O=arana"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	var (
+		dir    = t.TempDir()
+		ca     = newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+		server = newTestCert(t, pkix.Name{CommonName: "server"}, ca)
+		client = newTestCert(t, pkix.Name{CommonName: "client", Organization: []string{"arana"}}, ca)
+	)
+	caFile, _ := ca.writeTo(t, dir, "ca")
+	certFile, keyFile := server.writeTo(t, dir, "server")
+
+	pl, err := NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: 0},
+		ServerVersion: "5.7.0",
+		TLS:           &config.TLS{CertFile: certFile, KeyFile: keyFile, CAFile: caFile},
+	})
+	assert.NoError(t, err)
+	l := pl.(*Listener)
+	defer l.listener.Close()
+
+	go serveHandshake(l)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	assert.NoError(t, driver.RegisterTLSConfig("arana-test", &tls.Config{RootCAs: roots, ServerName: "localhost"}))
+	assert.NoError(t, driver.RegisterTLSConfig("arana-test-cert", &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{client.tlsCertificate()},
+	}))
+
+	connect := func(user, tlsConfig string) error {
+		return testConnect(l, cluster, user, "123456", tlsConfig)
+	}
+
+	assert.NoError(t, connect("plain", "false"))
+	assert.NoError(t, connect("plain", "arana-test"))
+
+	assert.NoError(t, connect("secure", "arana-test"))
+	assertAccessDenied(t, connect("secure", "false"))
+
+	assert.NoError(t, connect("cert", "arana-test-cert"))
+	assertAccessDenied(t, connect("cert", "arana-test"))
+}
+
+// serveHandshake serves the handshakes only, which is used to test the connection phase.
+func serveHandshake(l *Listener) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			c := newConn(conn)
+			if err := l.handshake(c); err != nil {
+				_ = c.writeErrorPacketFromError(err)
+				return
+			}
+			_ = c.writeOKPacket(0, 0, c.StatusFlags, 0)
+			_, _ = c.readEphemeralPacket()
+		}()
+	}
+}
+
+// testConnect connects to the listener by go-sql-driver.
+func testConnect(l *Listener, schema, user, password, tlsConfig string) error {
+	cfg := driver.NewConfig()
+	cfg.User, cfg.Passwd, cfg.DBName = user, password, schema
+	cfg.Net, cfg.Addr = "tcp", l.listener.Addr().String()
+	cfg.TLSConfig = tlsConfig
+	connector, err := driver.NewConnector(cfg)
+	if err != nil {
+		return err
+	}
+	conn, err := connector.Connect(context.Background())
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func assertAccessDenied(t *testing.T, err error) {
+	if se, ok := err.(*driver.MySQLError); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, uint16(mysql.ERAccessDeniedError), se.Number)
+	}
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+	caFile, _ := ca.writeTo(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, pkix.Name{CommonName: "server"}, ca).writeTo(t, dir, "server")
+
+	c, err := newServerTLSConfig(&config.TLS{CertFile: certFile, KeyFile: keyFile})
+	assert.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, c.ClientAuth)
+
+	c, err = newServerTLSConfig(&config.TLS{CertFile: certFile, KeyFile: keyFile, CAFile: caFile, RequireClientCert: true})
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, c.ClientAuth)
+
+	_, err = newServerTLSConfig(&config.TLS{CertFile: certFile, KeyFile: keyFile, RequireClientCert: true})
+	assert.Error(t, err)
+	_, err = newServerTLSConfig(&config.TLS{CertFile: certFile, KeyFile: caFile})
+	assert.Error(t, err)
+}
@@ -34,6 +34,15 @@ type (
 
 		Schema string
 
+		// Tenant is the tenant of current connection.
+		Tenant string
+
+		// Username is the user of current connection.
+		Username string
+
+		// RemoteAddr is the address of client.
+		RemoteAddr string
+
 		ConnectionID uint32
 
 		// sql Data
@@ -48,6 +57,9 @@ type (
 		Listen()
 
 		Close()
+
+		// Shutdown stops accepting new connections, and waits for the in-flight requests until ctx is done.
+		Shutdown(ctx context.Context)
 	}
 
 	Filter interface {
@@ -57,13 +69,18 @@ type (
 	// PreFilter
 	PreFilter interface {
 		Filter
-		PreHandle(ctx *Context)
+		// PreHandle handles the statement before it is optimized and executed:
+		//  - return an error to reject the statement, a *errors.SQLError will be sent to client with its error code.
+		//  - return a non-nil result to short-circuit the statement, the result will be sent to client directly.
+		//  - replace the ctx.Stmt to rewrite the statement, see filter.Rewrite.
+		PreHandle(ctx *Context) (Result, error)
 	}
 
 	// PostFilter
 	PostFilter interface {
 		Filter
-		PostHandle(ctx *Context, result Result)
+		// PostHandle handles the result of statement, the err is not nil if the statement failed.
+		PostHandle(ctx *Context, result Result, err error)
 	}
 
 	FilterFactory interface {
@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// commands of process
+const (
+	CommandSleep   = "Sleep"
+	CommandQuery   = "Query"
+	CommandExecute = "Execute"
+)
+
+// ProcessInfo is a snapshot of Process, which is a row of 'SHOW PROCESSLIST'.
+type ProcessInfo struct {
+	ID       uint32
+	Tenant   string
+	Username string
+	Host     string
+	Schema   string
+	Command  string
+	State    string
+	Info     string
+	Time     time.Duration
+}
+
+// Process tracks the running statement of a frontend connection.
+type Process struct {
+	id   uint32
+	host string
+
+	mu       sync.Mutex
+	tenant   string
+	username string
+	schema   string
+	command  string
+	state    string
+	info     string
+	since    time.Time
+	cancel   context.CancelFunc
+	killed   bool
+}
+
+// NewProcess creates a sleeping process.
+func NewProcess(id uint32, tenant, username, host, schema string) *Process {
+	return &Process{
+		id:       id,
+		tenant:   tenant,
+		username: username,
+		host:     host,
+		schema:   schema,
+		command:  CommandSleep,
+		since:    time.Now(),
+	}
+}
+
+// ID returns the connection id of process.
+func (p *Process) ID() uint32 {
+	return p.id
+}
+
+// Tenant returns the tenant of process.
+func (p *Process) Tenant() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tenant
+}
+
+// SetUser sets the user of process, which is changed by COM_CHANGE_USER.
+func (p *Process) SetUser(tenant, username, schema string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tenant, p.username, p.schema = tenant, username, schema
+}
+
+// SetSchema sets the current schema of process.
+func (p *Process) SetSchema(schema string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.schema = schema
+}
+
+// Begin marks the process as running the statement, the returned context will be canceled if the query is killed,
+// and then the queries on backend connections will be killed too.
+func (p *Process) Begin(ctx context.Context, command, info string) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.command, p.state, p.info = command, "executing", info
+	p.since = time.Now()
+	p.cancel = cancel
+	p.killed = false
+	return ctx
+}
+
+// End marks the process as sleeping, returns true if the statement has been killed.
+func (p *Process) End() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	killed := p.killed
+	p.command, p.state, p.info = CommandSleep, "", ""
+	p.since = time.Now()
+	p.killed = false
+	return killed
+}
+
+// KillQuery cancels the running statement.
+func (p *Process) KillQuery() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel == nil {
+		// nothing is running
+		return
+	}
+	p.cancel()
+	p.killed = true
+	p.state = "killed"
+}
+
+// Info returns the snapshot of process.
+func (p *Process) Info() ProcessInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ProcessInfo{
+		ID:       p.id,
+		Tenant:   p.tenant,
+		Username: p.username,
+		Host:     p.host,
+		Schema:   p.schema,
+		Command:  p.command,
+		State:    p.state,
+		Info:     p.info,
+		Time:     time.Since(p.since),
+	}
+}
@@ -19,8 +19,10 @@
 package rule
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sync"
+	"time"
 )
 
 import (
@@ -49,7 +51,8 @@ func (d DirectShardComputer) Compute(value interface{}) (int, error) {
 }
 
 const (
-	attrAllowFullScan byte = 0x01
+	attrAllowFullScan    byte = 0x01
+	attrMaxExecutionTime byte = 0x02
 )
 
 // VTable represents a virtual/logical table.
@@ -68,6 +71,22 @@ func (vt *VTable) AllowFullScan() bool {
 	return ret
 }
 
+// SetMaxExecutionTime sets the timeout of statements on the table.
+func (vt *VTable) SetMaxExecutionTime(timeout time.Duration) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(timeout))
+	vt.setAttribute(attrMaxExecutionTime, b[:])
+}
+
+// MaxExecutionTime returns the timeout of statements on the table, returns zero if not set.
+func (vt *VTable) MaxExecutionTime() time.Duration {
+	b, ok := vt.attribute(attrMaxExecutionTime)
+	if !ok || len(b) != 8 {
+		return 0
+	}
+	return time.Duration(binary.BigEndian.Uint64(b))
+}
+
 func (vt *VTable) GetShardKeys() []string {
 	keys := make([]string, 0, len(vt.shards))
 	for k := range vt.shards {
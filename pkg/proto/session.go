@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// VarNames is the name of variable set by 'SET NAMES', the value is like "'utf8mb4' COLLATE 'utf8mb4_bin'".
+	VarNames = "NAMES"
+	// VarCharset is the name of variable set by 'SET CHARACTER SET', the value is like "'utf8mb4'".
+	VarCharset = "CHARACTER SET"
+)
+
+// Variable is a session variable which will be replayed on backend connections.
+// The name is '@@name' for system variables, '@name' for user variables, or VarNames/VarCharset,
+// and the value is a SQL literal.
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// Session holds the state of a frontend session.
+type Session struct {
+	mu          sync.RWMutex
+	autocommit  bool
+	variables   []Variable
+	txIsolation string
+	// maxExecutionTime is the timeout of statements, zero means unlimited.
+	maxExecutionTime time.Duration
+}
+
+// NewSession creates a session with default state.
+func NewSession() *Session {
+	return &Session{autocommit: true}
+}
+
+// IsAutocommit returns true if the statements are committed automatically, a nil session is always autocommit.
+func (s *Session) IsAutocommit() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.autocommit
+}
+
+// SetAutocommit sets autocommit of session.
+func (s *Session) SetAutocommit(autocommit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autocommit = autocommit
+}
+
+// Variables returns the variables in the order they were set.
+func (s *Session) Variables() []Variable {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.variables) < 1 {
+		return nil
+	}
+	vars := make([]Variable, len(s.variables))
+	copy(vars, s.variables)
+	return vars
+}
+
+// SetVariables replaces all variables of session.
+func (s *Session) SetVariables(vars []Variable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.variables = append([]Variable(nil), vars...)
+}
+
+// SetVariable sets a variable and moves it to the last, the variable will be removed if value is empty.
+func (s *Session) SetVariable(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, it := range s.variables {
+		if it.Name == name {
+			s.variables = append(s.variables[:i], s.variables[i+1:]...)
+			break
+		}
+	}
+	if len(value) > 0 {
+		s.variables = append(s.variables, Variable{Name: name, Value: value})
+	}
+}
+
+// SetNextTxIsolation sets the isolation level of next transaction, eg: 'READ COMMITTED'.
+func (s *Session) SetNextTxIsolation(level string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txIsolation = level
+}
+
+// TakeNextTxIsolation returns and clears the isolation level of next transaction.
+func (s *Session) TakeNextTxIsolation() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	level := s.txIsolation
+	s.txIsolation = ""
+	return level
+}
+
+// SetMaxExecutionTime sets the timeout of statements, zero means unlimited.
+func (s *Session) SetMaxExecutionTime(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxExecutionTime = timeout
+}
+
+// MaxExecutionTime returns the timeout of statements, zero means unlimited.
+func (s *Session) MaxExecutionTime() time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxExecutionTime
+}
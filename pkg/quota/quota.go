@@ -0,0 +1,298 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package quota limits the QPS, concurrent queries and frontend connections of tenants and users.
+package quota
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"golang.org/x/time/rate"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+)
+
+// The resources of quota, which are shown in the error message.
+const (
+	ResourceQPS         = "max_queries_per_second"
+	ResourceConcurrency = "max_concurrent_queries"
+	ResourceConnections = "max_user_connections"
+)
+
+var _limiters sync.Map // tenant or tenant/user -> *Limiter
+
+func key(tenant, user string) string {
+	if len(user) < 1 {
+		return tenant
+	}
+	return tenant + "/" + user
+}
+
+// PutTenant puts the quotas of tenant and its users, the limiters of removed users will be removed.
+func PutTenant(tenant *config.Tenant) error {
+	if err := Put(tenant.Name, "", tenant.Quota); err != nil {
+		return err
+	}
+
+	users := make(map[string]struct{}, len(tenant.Users))
+	for _, it := range tenant.Users {
+		users[it.Username] = struct{}{}
+		if err := Put(tenant.Name, it.Username, it.Quota); err != nil {
+			return err
+		}
+	}
+
+	prefix := tenant.Name + "/"
+	_limiters.Range(func(k, _ interface{}) bool {
+		if user := strings.TrimPrefix(k.(string), prefix); user != k.(string) {
+			if _, ok := users[user]; !ok {
+				_limiters.Delete(k)
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// RemoveTenant removes the limiters of tenant and its users.
+func RemoveTenant(tenant string) {
+	prefix := tenant + "/"
+	_limiters.Range(func(k, _ interface{}) bool {
+		if k.(string) == tenant || strings.HasPrefix(k.(string), prefix) {
+			_limiters.Delete(k)
+		}
+		return true
+	})
+}
+
+// Put puts the quota of tenant, or the quota of user if user is not empty.
+// The existing limiter will be updated in place, and it will be removed if the quota is unlimited.
+func Put(tenant, user string, quota *config.Quota) error {
+	k := key(tenant, user)
+	if quota == nil || (quota.QPS < 1 && quota.MaxConcurrency < 1 && quota.MaxConnections < 1) {
+		_limiters.Delete(k)
+		return nil
+	}
+
+	timeout, err := quota.GetQueueTimeout()
+	if err != nil {
+		return err
+	}
+
+	v, _ := _limiters.LoadOrStore(k, newLimiter(k))
+	v.(*Limiter).update(quota, timeout)
+	return nil
+}
+
+// Get returns the limiter of tenant, or the limiter of user if user is not empty.
+func Get(tenant, user string) (*Limiter, bool) {
+	v, ok := _limiters.Load(key(tenant, user))
+	if !ok {
+		return nil, false
+	}
+	return v.(*Limiter), true
+}
+
+// Acquire acquires a query slot of both tenant and user, it may wait in a bounded queue.
+// The returned release func must be called when the query is finished.
+func Acquire(ctx context.Context, tenant, user string) (func(), error) {
+	return acquireAll(tenant, user, func(l *Limiter) (func(), error) {
+		return l.Acquire(ctx)
+	})
+}
+
+// AcquireConn acquires a frontend connection of both tenant and user.
+// The returned release func must be called when the connection is closed.
+func AcquireConn(tenant, user string) (func(), error) {
+	return acquireAll(tenant, user, (*Limiter).AcquireConn)
+}
+
+func acquireAll(tenant, user string, acquire func(l *Limiter) (func(), error)) (func(), error) {
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	for _, k := range []string{key(tenant, ""), key(tenant, user)} {
+		v, ok := _limiters.Load(k)
+		if !ok {
+			continue
+		}
+		r, err := acquire(v.(*Limiter))
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
+
+// Limiter limits the QPS, concurrent queries and connections.
+type Limiter struct {
+	name string
+
+	mu             sync.Mutex
+	qps            *rate.Limiter
+	maxConcurrency int
+	maxConnections int
+	queueSize      int
+	timeout        time.Duration
+
+	running     int
+	waiting     int
+	connections int
+	released    chan struct{}
+}
+
+func newLimiter(name string) *Limiter {
+	return &Limiter{
+		name:     name,
+		released: make(chan struct{}),
+	}
+}
+
+func (l *Limiter) update(quota *config.Quota, timeout time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case quota.QPS < 1:
+		l.qps = nil
+	case l.qps == nil:
+		l.qps = rate.NewLimiter(rate.Limit(quota.QPS), quota.QPS)
+	default:
+		l.qps.SetLimit(rate.Limit(quota.QPS))
+		l.qps.SetBurst(quota.QPS)
+	}
+	l.maxConcurrency = quota.MaxConcurrency
+	l.maxConnections = quota.MaxConnections
+	l.queueSize = quota.QueueSize
+	l.timeout = timeout
+
+	// wake up the waiting queries, since the limits may be raised
+	l.notify()
+}
+
+// Acquire acquires a query slot, it waits in the queue if over limits and the queue is not full.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	deadline := time.Now().Add(l.timeout)
+
+	if l.qps != nil {
+		r := l.qps.Reserve()
+		if delay := r.Delay(); delay > 0 {
+			if l.waiting >= l.queueSize || delay > l.timeout {
+				r.Cancel()
+				return nil, l.exceeded(ResourceQPS, l.qps.Burst())
+			}
+			timer := time.NewTimer(delay)
+			err := l.wait(ctx, nil, timer.C)
+			timer.Stop()
+			if err != nil {
+				r.Cancel()
+				return nil, err
+			}
+		}
+	}
+
+	for l.maxConcurrency > 0 && l.running >= l.maxConcurrency {
+		remaining := time.Until(deadline)
+		if l.waiting >= l.queueSize || remaining <= 0 {
+			return nil, l.exceeded(ResourceConcurrency, l.maxConcurrency)
+		}
+		timer := time.NewTimer(remaining)
+		err := l.wait(ctx, l.released, timer.C)
+		timer.Stop()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l.running++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.running--
+			l.notify()
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// wait releases the lock and waits until any of the channels is ready, the lock must be held.
+func (l *Limiter) wait(ctx context.Context, ready <-chan struct{}, timeout <-chan time.Time) error {
+	l.waiting++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+	}()
+
+	select {
+	case <-ready:
+	case <-timeout:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (l *Limiter) notify() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// AcquireConn acquires a frontend connection.
+func (l *Limiter) AcquireConn() (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxConnections > 0 && l.connections >= l.maxConnections {
+		return nil, err2.NewSQLError(mysql.ERConCount, mysql.SSConCount, "Too many connections of '%s' (current value: %d)", l.name, l.maxConnections)
+	}
+	l.connections++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.connections--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+func (l *Limiter) exceeded(resource string, value int) error {
+	return err2.NewSQLError(mysql.ERUserLimitReached, mysql.SSSyntaxErrorOrAccessViolation,
+		"'%s' has exceeded the '%s' resource (current value: %d)", l.name, resource, value)
+}
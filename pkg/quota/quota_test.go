@@ -0,0 +1,159 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+	"github.com/arana-db/arana/pkg/constants/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+)
+
+func assertSQLError(t *testing.T, err error, number int) {
+	se, ok := err.(*err2.SQLError)
+	if assert.True(t, ok, "%v", err) {
+		assert.Equal(t, number, se.Number())
+	}
+}
+
+func TestAcquireConn(t *testing.T) {
+	defer RemoveTenant("conn")
+	assert.NoError(t, PutTenant(&config.Tenant{
+		Name:  "conn",
+		Quota: &config.Quota{MaxConnections: 2},
+		Users: []*config.User{
+			{Username: "foo", Quota: &config.Quota{MaxConnections: 1}},
+			{Username: "bar"},
+		},
+	}))
+
+	release1, err := AcquireConn("conn", "foo")
+	assert.NoError(t, err)
+	_, err = AcquireConn("conn", "foo")
+	assertSQLError(t, err, mysql.ERConCount)
+
+	release2, err := AcquireConn("conn", "bar")
+	assert.NoError(t, err)
+	_, err = AcquireConn("conn", "bar")
+	assertSQLError(t, err, mysql.ERConCount)
+
+	release1()
+	release1()
+	release2()
+	_, err = AcquireConn("conn", "bar")
+	assert.NoError(t, err)
+}
+
+func TestAcquireConcurrency(t *testing.T) {
+	defer RemoveTenant("concurrency")
+	assert.NoError(t, PutTenant(&config.Tenant{
+		Name:  "concurrency",
+		Users: []*config.User{{Username: "foo", Quota: &config.Quota{MaxConcurrency: 1, QueueSize: 1, QueueTimeout: "2s"}}},
+	}))
+
+	release, err := Acquire(context.Background(), "concurrency", "foo")
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		r, err := Acquire(context.Background(), "concurrency", "foo")
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+
+	// wait until the query is queued, then the queue is full
+	assert.Eventually(t, func() bool {
+		l, _ := Get("concurrency", "foo")
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiting == 1
+	}, time.Second, 10*time.Millisecond)
+	_, err = Acquire(context.Background(), "concurrency", "foo")
+	assertSQLError(t, err, mysql.ERUserLimitReached)
+
+	release()
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release, err = Acquire(ctx, "concurrency", "foo")
+	assert.NoError(t, err)
+	defer release()
+	cancel()
+	_, err = Acquire(ctx, "concurrency", "foo")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAcquireQPS(t *testing.T) {
+	defer RemoveTenant("qps")
+	assert.NoError(t, Put("qps", "", &config.Quota{QPS: 1}))
+
+	release, err := Acquire(context.Background(), "qps", "foo")
+	assert.NoError(t, err)
+	release()
+	_, err = Acquire(context.Background(), "qps", "foo")
+	assertSQLError(t, err, mysql.ERUserLimitReached)
+
+	// raise the limit in place
+	assert.NoError(t, Put("qps", "", &config.Quota{QPS: 1, QueueSize: 1}))
+	start := time.Now()
+	release, err = Acquire(context.Background(), "qps", "foo")
+	assert.NoError(t, err)
+	release()
+	assert.Greater(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestPutTenant(t *testing.T) {
+	defer RemoveTenant("put")
+	tenant := &config.Tenant{
+		Name:  "put",
+		Quota: &config.Quota{QPS: 100},
+		Users: []*config.User{{Username: "foo", Quota: &config.Quota{QPS: 10}}},
+	}
+	assert.NoError(t, PutTenant(tenant))
+	_, ok := Get("put", "foo")
+	assert.True(t, ok)
+
+	tenant.Users = nil
+	assert.NoError(t, PutTenant(tenant))
+	_, ok = Get("put", "foo")
+	assert.False(t, ok)
+	_, ok = Get("put", "")
+	assert.True(t, ok)
+
+	tenant.Quota = &config.Quota{QueueTimeout: "1 second", QPS: 1}
+	assert.Error(t, PutTenant(tenant))
+
+	RemoveTenant("put")
+	_, ok = Get("put", "")
+	assert.False(t, ok)
+
+	release, err := Acquire(context.Background(), "put", "foo")
+	assert.NoError(t, err)
+	release()
+}
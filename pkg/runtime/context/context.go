@@ -40,6 +40,7 @@ type (
 	keyNodeLabel      struct{}
 	keySchema         struct{}
 	keyDefaultDBGroup struct{}
+	keySession        struct{}
 )
 
 type cFlag uint8
@@ -79,6 +80,11 @@ func WithSequencer(ctx context.Context, sequencer proto.Sequencer) context.Conte
 	return context.WithValue(ctx, keySequence{}, sequencer)
 }
 
+// WithSession binds the frontend session, its variables will be replayed on backend connections.
+func WithSession(ctx context.Context, session *proto.Session) context.Context {
+	return context.WithValue(ctx, keySession{}, session)
+}
+
 // WithWrite marked as write operation
 func WithWrite(ctx context.Context) context.Context {
 	return context.WithValue(ctx, keyFlag{}, _flagWrite|getFlag(ctx))
@@ -146,6 +152,14 @@ func Schema(ctx context.Context) string {
 	return ""
 }
 
+// Session extracts the frontend session, returns nil if not exists.
+func Session(ctx context.Context) *proto.Session {
+	if session, ok := ctx.Value(keySession{}).(*proto.Session); ok {
+		return session
+	}
+	return nil
+}
+
 // NodeLabel returns the label of node.
 func NodeLabel(ctx context.Context) string {
 	if label, ok := ctx.Value(keyNodeLabel{}).(string); ok {
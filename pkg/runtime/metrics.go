@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+)
+
+var _poolLabels = []string{"namespace", "group", "node"}
+
+var (
+	_poolCapacity    = newPoolDesc("capacity", "Capacity of the connection pool.")
+	_poolMaxCapacity = newPoolDesc("max_capacity", "Max capacity of the connection pool.")
+	_poolAvailable   = newPoolDesc("available", "Number of available connections in the pool.")
+	_poolActive      = newPoolDesc("active", "Number of active connections in the pool.")
+	_poolInUse       = newPoolDesc("in_use", "Number of connections in use.")
+	_poolWaitCount   = newPoolDesc("wait_count_total", "Number of waits for a connection.")
+	_poolWaitTime    = newPoolDesc("wait_time_seconds_total", "Total time waited for a connection.")
+	_poolIdleClosed  = newPoolDesc("idle_closed_total", "Number of connections closed due to idle timeout.")
+	_poolExhausted   = newPoolDesc("exhausted_total", "Number of times the pool was exhausted.")
+)
+
+func init() {
+	prometheus.MustRegister(poolCollector{})
+}
+
+func newPoolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName("arana", "pool", name), help, _poolLabels, nil)
+}
+
+// poolCollector collects the connection pool statistics of all AtomDB.
+type poolCollector struct{}
+
+func (poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- _poolCapacity
+	ch <- _poolMaxCapacity
+	ch <- _poolAvailable
+	ch <- _poolActive
+	ch <- _poolInUse
+	ch <- _poolWaitCount
+	ch <- _poolWaitTime
+	ch <- _poolIdleClosed
+	ch <- _poolExhausted
+}
+
+func (poolCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, name := range namespace.List() {
+		ns := namespace.Load(name)
+		if ns == nil {
+			continue
+		}
+		for _, group := range ns.DBGroups() {
+			for _, it := range ns.DBs(group) {
+				db, ok := it.(*AtomDB)
+				if !ok {
+					continue
+				}
+				var (
+					stats  = db.Stats()
+					labels = []string{name, group, db.ID()}
+				)
+				ch <- prometheus.MustNewConstMetric(_poolCapacity, prometheus.GaugeValue, float64(stats.Capacity), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolMaxCapacity, prometheus.GaugeValue, float64(stats.MaxCapacity), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolAvailable, prometheus.GaugeValue, float64(stats.Available), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolActive, prometheus.GaugeValue, float64(stats.Active), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolInUse, prometheus.GaugeValue, float64(stats.InUse), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolWaitCount, prometheus.CounterValue, float64(stats.WaitCount), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolWaitTime, prometheus.CounterValue, stats.WaitTime.Seconds(), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolIdleClosed, prometheus.CounterValue, float64(stats.IdleClosed), labels...)
+				ch <- prometheus.MustNewConstMetric(_poolExhausted, prometheus.CounterValue, float64(stats.Exhausted), labels...)
+			}
+		}
+	}
+}
@@ -88,11 +88,19 @@ func RemoveDB(group, id string) Command {
 		for k, v := range dss {
 			newborn[k] = v
 		}
-		newborn[group] = values
-
-		// TODO: expire datasource, lazy-close?
+		if len(values) > 0 {
+			newborn[group] = values
+		} else {
+			delete(newborn, group)
+		}
 
 		ns.dss.Store(newborn)
+
+		// the pool will be closed lazily after all pending requests finished
+		if err := expired.Close(); err != nil {
+			log.Errorf("[%s] close expired datasource %s.%s failed: %v", ns.name, group, id, err)
+		}
+
 		log.Infof("[%s] remove datasource %s.%s successfully", ns.name, group, id)
 	}
 }
@@ -121,11 +129,6 @@ func UpsertDB(group string, ds proto.DB) Command {
 		}
 		values = append(values, ds)
 
-		if expired != nil {
-			// TODO: expire datasource, lazy-close?
-			log.Infof("todo: expire DB %s", expired.ID())
-		}
-
 		newborn := make(map[string][]proto.DB)
 		for k, v := range current {
 			newborn[k] = v
@@ -134,6 +137,13 @@ func UpsertDB(group string, ds proto.DB) Command {
 
 		ns.dss.Store(newborn)
 
+		if expired != nil {
+			// the pool will be closed lazily after all pending requests finished
+			if err := expired.Close(); err != nil {
+				log.Errorf("[%s] close expired db %s.%s failed: %v", ns.name, group, id, err)
+			}
+		}
+
 		log.Infof("[%s] upsert db %s.%s successfully", ns.name, group, id)
 	}
 }
@@ -49,6 +49,17 @@ func Load(namespace string) *Namespace {
 	return exist.(*Namespace)
 }
 
+// List returns the names of all registered namespaces.
+func List() []string {
+	var names []string
+	_namespaces.Range(func(key, _ interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
 // Register registers a namespace.
 func Register(namespace *Namespace) error {
 	name := namespace.Name()
@@ -135,6 +146,12 @@ func (ns *Namespace) DB0(ctx context.Context) proto.DB {
 	return ns.DB(ctx, groups[0])
 }
 
+// DBs returns all DB of the group.
+func (ns *Namespace) DBs(group string) []proto.DB {
+	dss := ns.dss.Load().(map[string][]proto.DB)
+	return append([]proto.DB(nil), dss[group]...)
+}
+
 // DB returns a DB, returns nil if nothing selected.
 func (ns *Namespace) DB(ctx context.Context, group string) proto.DB {
 	// use weight manager to select datasource
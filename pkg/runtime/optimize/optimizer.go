@@ -21,6 +21,7 @@ import (
 	"context"
 	stdErrors "errors"
 	"strings"
+	"time"
 )
 
 import (
@@ -30,6 +31,7 @@ import (
 )
 
 import (
+	"github.com/arana-db/arana/pkg/metrics"
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/proto/schema_manager"
@@ -37,6 +39,8 @@ import (
 	"github.com/arana-db/arana/pkg/runtime/cmp"
 	rcontext "github.com/arana-db/arana/pkg/runtime/context"
 	"github.com/arana-db/arana/pkg/runtime/plan"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -72,6 +76,13 @@ type optimizer struct {
 }
 
 func (o optimizer) Optimize(ctx context.Context, conn proto.VConn, stmt ast.StmtNode, args ...interface{}) (plan proto.Plan, err error) {
+	ctx, span := trace.Start(ctx, "optimize")
+	start := time.Now()
+	defer func() {
+		slowlog.FromContext(ctx).SetOptimize(time.Since(start))
+		trace.End(span, err)
+	}()
+
 	defer func() {
 		if rec := recover(); rec != nil {
 			err = errors.Errorf("cannot analyze sql %s", rcontext.SQL(ctx))
@@ -187,6 +198,8 @@ func (o optimizer) optimizeSelect(ctx context.Context, conn proto.VConn, stmt *r
 		return nil, errors.WithStack(errDenyFullScan)
 	}
 
+	observeShards(ctx, vt, shards, fullScan)
+
 	if shards.IsEmpty() {
 		var (
 			db0, tbl0 string
@@ -326,6 +339,8 @@ func (o optimizer) optimizeUpdate(ctx context.Context, conn proto.VConn, stmt *r
 		return nil, errDenyFullScan
 	}
 
+	observeShards(ctx, vt, shards, fullScan)
+
 	// must be empty shards (eg: update xxx set ... where 1 = 2 and uid = 1)
 	if shards.IsEmpty() {
 		return plan.AlwaysEmptyExecPlan{}, nil
@@ -436,6 +451,12 @@ func (o optimizer) optimizeInsert(ctx context.Context, conn proto.VConn, stmt *r
 		slots[db][table] = append(slots[db][table], i)
 	}
 
+	var fanout int
+	for _, slot := range slots {
+		fanout += len(slot)
+	}
+	metrics.ObserveShards(fanout, false)
+
 	for db, slot := range slots {
 		for table, indexes := range slot {
 			// clone insert stmt without values
@@ -459,7 +480,7 @@ func (o optimizer) optimizeInsert(ctx context.Context, conn proto.VConn, stmt *r
 
 func (o optimizer) optimizeDelete(ctx context.Context, stmt *rast.DeleteStatement, args []interface{}) (proto.Plan, error) {
 	ru := rcontext.Rule(ctx)
-	shards, err := o.computeShards(ru, stmt.Table, stmt.Where, args)
+	shards, err := o.computeShards(ctx, ru, stmt.Table, stmt.Where, args)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to optimize DELETE statement")
 	}
@@ -479,7 +500,7 @@ func (o optimizer) optimizeDelete(ctx context.Context, stmt *rast.DeleteStatemen
 
 func (o optimizer) optimizeTruncate(ctx context.Context, stmt *rast.TruncateStatement, args []interface{}) (proto.Plan, error) {
 	ru := rcontext.Rule(ctx)
-	shards, err := o.computeShards(ru, stmt.Table, nil, args)
+	shards, err := o.computeShards(ctx, ru, stmt.Table, nil, args)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to optimize TRUNCATE statement")
 	}
@@ -495,7 +516,7 @@ func (o optimizer) optimizeTruncate(ctx context.Context, stmt *rast.TruncateStat
 	return ret, nil
 }
 
-func (o optimizer) computeShards(ru *rule.Rule, table rast.TableName, where rast.ExpressionNode, args []interface{}) (rule.DatabaseTables, error) {
+func (o optimizer) computeShards(ctx context.Context, ru *rule.Rule, table rast.TableName, where rast.ExpressionNode, args []interface{}) (rule.DatabaseTables, error) {
 	vt, ok := ru.VTable(table.Suffix())
 	if !ok {
 		return nil, nil
@@ -513,6 +534,8 @@ func (o optimizer) computeShards(ru *rule.Rule, table rast.TableName, where rast
 		return nil, errors.WithStack(errDenyFullScan)
 	}
 
+	observeShards(ctx, vt, shards, fullScan)
+
 	if shards.IsEmpty() {
 		return shards, nil
 	}
@@ -532,3 +555,20 @@ func (o optimizer) computeShards(ru *rule.Rule, table rast.TableName, where rast
 
 	return shards, nil
 }
+
+// observeShards records the fan-out width of a sharding query, the full-scan shards will be expanded by topology.
+func observeShards(ctx context.Context, vt *rule.VTable, shards rule.DatabaseTables, fullScan bool) {
+	if fullScan {
+		slowlog.FromContext(ctx).MarkFullScan()
+	}
+
+	fanout := shards.Len()
+	if shards.IsFullScan() {
+		fanout = 0
+		vt.Topology().Each(func(_, _ int) bool {
+			fanout++
+			return true
+		})
+	}
+	metrics.ObserveShards(fanout, fullScan)
+}
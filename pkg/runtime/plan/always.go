@@ -24,6 +24,7 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/mysql"
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*AlwaysEmptyExecPlan)(nil)
@@ -39,5 +40,8 @@ func (a AlwaysEmptyExecPlan) Type() proto.PlanType {
 }
 
 func (a AlwaysEmptyExecPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	_, span := trace.Start(ctx, "AlwaysEmptyExecPlan.ExecIn")
+	defer span.End()
+
 	return &_emptyResult, nil
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/arana-db/arana/pkg/runtime/ast"
 	rcontext "github.com/arana-db/arana/pkg/runtime/context"
 	"github.com/arana-db/arana/pkg/security"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var tenantErr = errors.New("current db tenant not fund")
@@ -46,7 +47,12 @@ func (s *ShowDatabasesPlan) Type() proto.PlanType {
 	return proto.PlanTypeQuery
 }
 
-func (s *ShowDatabasesPlan) ExecIn(ctx context.Context, _ proto.VConn) (proto.Result, error) {
+func (s *ShowDatabasesPlan) ExecIn(ctx context.Context, _ proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "ShowDatabasesPlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	tenant, ok := security.DefaultTenantManager().GetTenantOfCluster(rcontext.Schema(ctx))
 	if !ok {
 		return nil, tenantErr
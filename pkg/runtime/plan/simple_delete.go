@@ -31,6 +31,7 @@ import (
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*SimpleDeletePlan)(nil)
@@ -51,7 +52,12 @@ func (s *SimpleDeletePlan) Type() proto.PlanType {
 	return proto.PlanTypeExec
 }
 
-func (s *SimpleDeletePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (s *SimpleDeletePlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "SimpleDeletePlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	if s.shards == nil || s.shards.IsEmpty() {
 		return &mysql.Result{AffectedRows: 0}, nil
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/arana-db/arana/pkg/mysql"
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*SimpleInsertPlan)(nil)
@@ -53,7 +54,12 @@ func (sp *SimpleInsertPlan) Put(db string, stmt *ast.InsertStatement) {
 	sp.batch[db] = append(sp.batch[db], stmt)
 }
 
-func (sp *SimpleInsertPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (sp *SimpleInsertPlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "SimpleInsertPlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	var (
 		effected     uint64
 		lastInsertId uint64
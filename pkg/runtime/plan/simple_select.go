@@ -29,6 +29,7 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*SimpleQueryPlan)(nil)
@@ -44,12 +45,15 @@ func (s *SimpleQueryPlan) Type() proto.PlanType {
 	return proto.PlanTypeQuery
 }
 
-func (s *SimpleQueryPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (s *SimpleQueryPlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "SimpleQueryPlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	var (
 		sb      strings.Builder
 		indexes []int
-		res     proto.Result
-		err     error
 	)
 
 	if err = s.generate(&sb, &indexes); err != nil {
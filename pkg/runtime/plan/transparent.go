@@ -29,6 +29,7 @@ import (
 import (
 	"github.com/arana-db/arana/pkg/proto"
 	rast "github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*TransparentPlan)(nil)
@@ -70,11 +71,15 @@ func (tp *TransparentPlan) Type() proto.PlanType {
 	return tp.typ
 }
 
-func (tp *TransparentPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (tp *TransparentPlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "TransparentPlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	var (
 		sb   strings.Builder
 		args []int
-		err  error
 	)
 
 	if err = tp.stmt.Restore(rast.RestoreDefault, &sb, &args); err != nil {
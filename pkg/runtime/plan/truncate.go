@@ -31,6 +31,7 @@ import (
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 var _ proto.Plan = (*TruncatePlan)(nil)
@@ -50,7 +51,12 @@ func (s *TruncatePlan) Type() proto.PlanType {
 	return proto.PlanTypeExec
 }
 
-func (s *TruncatePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (s *TruncatePlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "TruncatePlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	if s.shards == nil || s.shards.IsEmpty() {
 		return &mysql.Result{AffectedRows: 0}, nil
 	}
@@ -27,6 +27,7 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/trace"
 )
 
 // UnionPlan merges multiple query plan.
@@ -38,7 +39,12 @@ func (u UnionPlan) Type() proto.PlanType {
 	return proto.PlanTypeQuery
 }
 
-func (u UnionPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (u UnionPlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "UnionPlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	var results []proto.Result
 	for _, it := range u.Plans {
 		res, err := it.ExecIn(ctx, conn)
@@ -35,6 +35,7 @@ import (
 	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 )
 
@@ -56,7 +57,12 @@ func (up *UpdatePlan) Type() proto.PlanType {
 	return proto.PlanTypeExec
 }
 
-func (up *UpdatePlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+func (up *UpdatePlan) ExecIn(ctx context.Context, conn proto.VConn) (res proto.Result, err error) {
+	ctx, span := trace.Start(ctx, "UpdatePlan.ExecIn")
+	defer func() {
+		trace.End(span, err)
+	}()
+
 	if up.shards == nil {
 		var sb strings.Builder
 		if err := up.stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
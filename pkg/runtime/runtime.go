@@ -22,7 +22,6 @@ import (
 	"encoding/json"
 	stdErrors "errors"
 	"fmt"
-	"sort"
 	"sync"
 	"time"
 )
@@ -39,10 +38,15 @@ import (
 
 import (
 	"github.com/arana-db/arana/pkg/config"
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/metrics"
 	"github.com/arana-db/arana/pkg/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
 	"github.com/arana-db/arana/pkg/proto"
 	rcontext "github.com/arana-db/arana/pkg/runtime/context"
 	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/slowlog"
+	"github.com/arana-db/arana/pkg/trace"
 	"github.com/arana-db/arana/pkg/util/log"
 	"github.com/arana-db/arana/pkg/util/rand2"
 	"github.com/arana-db/arana/third_party/pools"
@@ -58,6 +62,17 @@ var (
 	errTxClosed = stdErrors.New("transaction is closed")
 )
 
+// _killQueryTimeout is the timeout of killing a query on backend, including connecting to it.
+const _killQueryTimeout = 3 * time.Second
+
+// _draining is true if the server is shutting down, the new transactions will be rejected.
+var _draining atomic.Bool
+
+// Drain rejects the new transactions, it's called when the server is shutting down.
+func Drain() {
+	_draining.Store(true)
+}
+
 func NewAtomDB(node *config.Node) *AtomDB {
 	if node == nil {
 		return nil
@@ -107,6 +122,8 @@ var (
 type compositeTx struct {
 	closed atomic.Bool
 	id     int64
+	// isolation is the isolation level of transaction, empty means the isolation level of session.
+	isolation string
 
 	rt  *defaultRuntime
 	txs map[string]*atomTx
@@ -132,7 +149,7 @@ func (tx *compositeTx) call(ctx context.Context, db string, query string, args .
 
 	log.Debugf("call upstream: db=%s, sql=\"%s\", args=%v", db, query, args)
 
-	res, _, err := atx.Call(ctx, query, args...)
+	res, _, err := atx.Call(rcontext.WithDBGroup(ctx, db), query, args...)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -148,7 +165,7 @@ func (tx *compositeTx) begin(ctx context.Context, group string) (*atomTx, error)
 	ctx = rcontext.WithWrite(ctx)
 
 	// begin atom tx
-	newborn, err := tx.rt.Namespace().DB(ctx, group).(*AtomDB).begin(ctx)
+	newborn, err := tx.rt.Namespace().DB(ctx, group).(*AtomDB).begin(ctx, tx.isolation)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +185,11 @@ func (tx *compositeTx) Execute(ctx *proto.Context) (res proto.Result, warn uint1
 
 	var (
 		args = tx.rt.extractArgs(ctx)
+		plan proto.Plan
 	)
+
+	defer observeQuery(ctx, &plan, time.Now(), &err)
+
 	if direct := rcontext.IsDirect(ctx.Context); direct {
 		var (
 			group = tx.rt.Namespace().DBGroups()[0]
@@ -178,14 +199,13 @@ func (tx *compositeTx) Execute(ctx *proto.Context) (res proto.Result, warn uint1
 		if atx, err = tx.begin(cctx, group); err != nil {
 			return
 		}
-		res, warn, err = atx.Call(cctx, ctx.GetQuery(), args...)
+		res, warn, err = atx.Call(rcontext.WithDBGroup(cctx, group), ctx.GetQuery(), args...)
 		return
 	}
 
 	var (
-		ru   = tx.rt.ns.Rule()
-		plan proto.Plan
-		c    = ctx.Context
+		ru = tx.rt.ns.Rule()
+		c  = ctx.Context
 	)
 
 	c = rcontext.WithRule(c, ru)
@@ -209,11 +229,15 @@ func (tx *compositeTx) ID() int64 {
 	return tx.id
 }
 
-func (tx *compositeTx) Commit(ctx context.Context) (proto.Result, uint16, error) {
+func (tx *compositeTx) Commit(ctx context.Context) (res proto.Result, warn uint16, err error) {
 	if !tx.closed.CAS(false, true) {
 		return nil, 0, errTxClosed
 	}
 
+	defer func() {
+		metrics.ObserveTx(metrics.TxCommit, err)
+	}()
+
 	defer func() { // cleanup
 		tx.rt = nil
 		tx.txs = nil
@@ -233,7 +257,7 @@ func (tx *compositeTx) Commit(ctx context.Context) (proto.Result, uint16, error)
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	if err = g.Wait(); err != nil {
 		return nil, 0, err
 	}
 
@@ -242,11 +266,15 @@ func (tx *compositeTx) Commit(ctx context.Context) (proto.Result, uint16, error)
 	return &mysql.Result{}, 0, nil
 }
 
-func (tx *compositeTx) Rollback(ctx context.Context) (proto.Result, uint16, error) {
+func (tx *compositeTx) Rollback(ctx context.Context) (res proto.Result, warn uint16, err error) {
 	if !tx.closed.CAS(false, true) {
 		return nil, 0, errTxClosed
 	}
 
+	defer func() {
+		metrics.ObserveTx(metrics.TxRollback, err)
+	}()
+
 	defer func() { // cleanup
 		tx.rt = nil
 		tx.txs = nil
@@ -266,7 +294,7 @@ func (tx *compositeTx) Rollback(ctx context.Context) (proto.Result, uint16, erro
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	if err = g.Wait(); err != nil {
 		return nil, 0, err
 	}
 
@@ -302,6 +330,29 @@ func (tx *atomTx) Rollback(ctx context.Context) (res proto.Result, warn uint16,
 }
 
 func (tx *atomTx) Call(ctx context.Context, sql string, args ...interface{}) (res proto.Result, warn uint16, err error) {
+	_, span := trace.Start(ctx, "atomTx.Call",
+		trace.KeyGroup.String(rcontext.DBGroup(ctx)),
+		trace.KeyNode.String(tx.parent.id),
+		trace.KeySQL.String(sql),
+	)
+	start := time.Now()
+	defer func() {
+		slowlog.FromContext(ctx).AddCall(rcontext.DBGroup(ctx), tx.parent.id, sql, time.Since(start), err)
+		trace.End(span, err)
+	}()
+
+	// the session variables may be changed during the transaction
+	if err = tx.bc.SyncVariables(rcontext.Session(ctx).Variables()); err != nil {
+		return
+	}
+
+	stop := watchQuery(ctx, tx.bc)
+	defer func() {
+		if !stop() {
+			log.Warnf("the query may be still running on the connection of transaction: %s", sql)
+		}
+	}()
+
 	if len(args) > 0 {
 		res, warn, err = tx.bc.PrepareQueryArgs(sql, args)
 	} else {
@@ -332,6 +383,20 @@ func (tx *atomTx) dispose() {
 	}
 }
 
+// PoolStats represents the statistics of connection pool.
+type PoolStats struct {
+	Capacity    int64         `json:"capacity"`
+	MaxCapacity int64         `json:"max_capacity"`
+	Available   int64         `json:"available"`
+	Active      int64         `json:"active"`
+	InUse       int64         `json:"in_use"`
+	WaitCount   int64         `json:"wait_count"`
+	WaitTime    time.Duration `json:"wait_time"`
+	IdleTimeout time.Duration `json:"idle_timeout"`
+	IdleClosed  int64         `json:"idle_closed"`
+	Exhausted   int64         `json:"exhausted"`
+}
+
 type AtomDB struct {
 	id string
 
@@ -343,7 +408,7 @@ type AtomDB struct {
 	pendingRequests atomic.Int64
 }
 
-func (db *AtomDB) begin(ctx context.Context) (*atomTx, error) {
+func (db *AtomDB) begin(ctx context.Context, isolation string) (*atomTx, error) {
 	if db.closed.Load() {
 		return nil, errors.Errorf("the db instance '%s' is closed already", db.id)
 	}
@@ -359,7 +424,13 @@ func (db *AtomDB) begin(ctx context.Context) (*atomTx, error) {
 
 	db.pendingRequests.Inc()
 
-	if _, _, err = bc.ExecuteWithWarningCount("begin", true); err != nil {
+	if len(isolation) > 0 {
+		_, _, err = bc.ExecuteWithWarningCount("SET TRANSACTION ISOLATION LEVEL "+isolation, false)
+	}
+	if err == nil {
+		_, _, err = bc.ExecuteWithWarningCount("begin", true)
+	}
+	if err != nil {
 		// cleanup if failed to begin tx
 		cnt := db.pendingRequests.Dec()
 		db.returnConnection(bc)
@@ -377,6 +448,9 @@ func (db *AtomDB) CallFieldList(ctx context.Context, table, wildcard string) ([]
 		return nil, errors.Errorf("the db instance '%s' is closed already", db.id)
 	}
 
+	// the pool will be closed after the connection is returned if the db is closed meanwhile
+	defer db.pending()()
+
 	var (
 		bc  *mysql.BackendConnection
 		err error
@@ -387,7 +461,6 @@ func (db *AtomDB) CallFieldList(ctx context.Context, table, wildcard string) ([]
 	}
 
 	defer db.returnConnection(bc)
-	defer db.pending()
 
 	if err = bc.WriteComFieldList(table, wildcard); err != nil {
 		return nil, errors.WithStack(err)
@@ -397,11 +470,25 @@ func (db *AtomDB) CallFieldList(ctx context.Context, table, wildcard string) ([]
 }
 
 func (db *AtomDB) Call(ctx context.Context, sql string, args ...interface{}) (res proto.Result, warn uint16, err error) {
+	ctx, span := trace.Start(ctx, "AtomDB.Call",
+		trace.KeyGroup.String(rcontext.DBGroup(ctx)),
+		trace.KeyNode.String(db.id),
+		trace.KeySQL.String(sql),
+	)
+	start := time.Now()
+	defer func() {
+		slowlog.FromContext(ctx).AddCall(rcontext.DBGroup(ctx), db.id, sql, time.Since(start), err)
+		trace.End(span, err)
+	}()
+
 	if db.closed.Load() {
 		err = errors.Errorf("the db instance '%s' is closed already", db.id)
 		return
 	}
 
+	// the pool will be closed after the connection is returned if the db is closed meanwhile
+	defer db.pending()()
+
 	var bc *mysql.BackendConnection
 
 	if bc, err = db.borrowConnection(ctx); err != nil {
@@ -409,8 +496,15 @@ func (db *AtomDB) Call(ctx context.Context, sql string, args ...interface{}) (re
 		return
 	}
 
-	defer db.returnConnection(bc)
-	defer db.pending()
+	stop := watchQuery(ctx, bc)
+	defer func() {
+		if stop() {
+			db.returnConnection(bc)
+		} else {
+			// the query may be still running, so the connection cannot be reused
+			db.discardConnection(bc)
+		}
+	}()
 
 	if len(args) > 0 {
 		res, warn, err = bc.PrepareQueryArgs(sql, args)
@@ -439,6 +533,22 @@ func (db *AtomDB) pending() func() {
 	}
 }
 
+// Stats returns the statistics of connection pool.
+func (db *AtomDB) Stats() PoolStats {
+	return PoolStats{
+		Capacity:    db.pool.Capacity(),
+		MaxCapacity: db.pool.MaxCap(),
+		Available:   db.pool.Available(),
+		Active:      db.pool.Active(),
+		InUse:       db.pool.InUse(),
+		WaitCount:   db.pool.WaitCount(),
+		WaitTime:    db.pool.WaitTime(),
+		IdleTimeout: db.pool.IdleTimeout(),
+		IdleClosed:  db.pool.IdleClosed(),
+		Exhausted:   db.pool.Exhausted(),
+	}
+}
+
 func (db *AtomDB) ID() string {
 	return db.id
 }
@@ -478,27 +588,76 @@ func (db *AtomDB) SetWeight(weight proto.Weight) error {
 	return nil
 }
 
+// borrowConnection borrows a connection, and replays the variables of session on it.
 func (db *AtomDB) borrowConnection(ctx context.Context) (*mysql.BackendConnection, error) {
 	res, err := db.pool.Get(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return res.(*mysql.BackendConnection), nil
+	bc := res.(*mysql.BackendConnection)
+	if err = bc.SyncVariables(rcontext.Session(ctx).Variables()); err != nil {
+		db.returnConnection(bc)
+		return nil, err
+	}
+	return bc, nil
 }
 
 func (db *AtomDB) returnConnection(bc *mysql.BackendConnection) {
 	db.pool.Put(bc)
 }
 
+// discardConnection closes the connection, and a new one will be created for the pool.
+func (db *AtomDB) discardConnection(bc *mysql.BackendConnection) {
+	bc.Close()
+	db.pool.Put(nil)
+}
+
+// watchQuery kills the running query on the connection once the context is done, eg: timeout or killed by client.
+// The returned function stops watching, and reports false if the query may be still running on backend.
+func watchQuery(ctx context.Context, bc *mysql.BackendConnection) (stop func() bool) {
+	if ctx.Done() == nil {
+		return func() bool { return true }
+	}
+
+	var (
+		done   = make(chan struct{})
+		killed = make(chan error, 1)
+	)
+	go func() {
+		select {
+		case <-done:
+			killed <- nil
+		case <-ctx.Done():
+			// the context is done already, so kill it with a new one
+			kctx, cancel := context.WithTimeout(context.Background(), _killQueryTimeout)
+			err := bc.KillQuery(kctx)
+			cancel()
+			if err != nil {
+				log.Errorf("failed to kill query: %v", err)
+			}
+			killed <- err
+		}
+	}()
+
+	return func() bool {
+		close(done)
+		return <-killed == nil
+	}
+}
+
 type defaultRuntime struct {
 	ns *namespace.Namespace
 }
 
 func (pi *defaultRuntime) Begin(ctx *proto.Context) (proto.Tx, error) {
+	if _draining.Load() {
+		return nil, err2.NewSQLError(consts.ERServerShutdown, consts.SSServerShutdown, "Server shutdown in progress")
+	}
 	tx := &compositeTx{
-		id:  nextTxID(),
-		rt:  pi,
-		txs: make(map[string]*atomTx),
+		id:        nextTxID(),
+		isolation: rcontext.Session(ctx.Context).TakeNextTxIsolation(),
+		rt:        pi,
+		txs:       make(map[string]*atomTx),
 	}
 	log.Debugf("begin transaction: %s", tx.String())
 	return tx, nil
@@ -519,16 +678,20 @@ func (pi *defaultRuntime) Exec(ctx context.Context, db string, query string, arg
 }
 
 func (pi *defaultRuntime) Execute(ctx *proto.Context) (res proto.Result, warn uint16, err error) {
-	args := pi.extractArgs(ctx)
+	var (
+		args = pi.extractArgs(ctx)
+		plan proto.Plan
+	)
+
+	defer observeQuery(ctx, &plan, time.Now(), &err)
 
 	if direct := rcontext.IsDirect(ctx.Context); direct {
 		return pi.ns.DB0(ctx.Context).Call(rcontext.WithWrite(ctx.Context), ctx.GetQuery(), args...)
 	}
 
 	var (
-		ru   = pi.ns.Rule()
-		plan proto.Plan
-		c    = ctx.Context
+		ru = pi.ns.Rule()
+		c  = ctx.Context
 	)
 
 	c = rcontext.WithRule(c, ru)
@@ -550,22 +713,31 @@ func (pi *defaultRuntime) Execute(ctx *proto.Context) (res proto.Result, warn ui
 	return
 }
 
+// observeQuery records the metrics of an executed query, the plan type will be direct if no plan optimized.
+func observeQuery(ctx *proto.Context, plan *proto.Plan, start time.Time, err *error) {
+	var stmtType, planType string
+	if ctx.Stmt != nil {
+		stmtType = metrics.StmtType(ctx.Stmt.StmtNode)
+	} else {
+		stmtType = metrics.StmtType(nil)
+	}
+	if *plan == nil && rcontext.IsDirect(ctx.Context) {
+		planType = metrics.PlanDirect
+	} else {
+		planType = metrics.PlanType(*plan)
+	}
+	metrics.ObserveQuery(stmtType, planType, start, *err)
+}
+
 func (pi *defaultRuntime) extractArgs(ctx *proto.Context) []interface{} {
 	if ctx.Stmt == nil || len(ctx.Stmt.BindVars) < 1 {
 		return nil
 	}
 
-	var (
-		keys = make([]string, 0, len(ctx.Stmt.BindVars))
-		args = make([]interface{}, 0, len(ctx.Stmt.BindVars))
-	)
-
-	for k := range ctx.Stmt.BindVars {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		args = append(args, ctx.Stmt.BindVars[k])
+	// the bind variables are named by the order of parameters, eg: 'v1', 'v2' ... 'v10'
+	args := make([]interface{}, 0, len(ctx.Stmt.BindVars))
+	for i := 1; i <= len(ctx.Stmt.BindVars); i++ {
+		args = append(args, ctx.Stmt.BindVars[fmt.Sprintf("v%d", i)])
 	}
 	return args
 }
@@ -585,7 +757,7 @@ func (pi *defaultRuntime) call(ctx context.Context, group, query string, args ..
 	log.Debugf("call upstream: db=%s, sql=\"%s\", args=%v", group, query, args)
 
 	// TODO: how to pass warn???
-	res, _, err := db.Call(ctx, query, args...)
+	res, _, err := db.Call(rcontext.WithDBGroup(ctx, group), query, args...)
 	return res, err
 }
 
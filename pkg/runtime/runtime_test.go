@@ -18,18 +18,31 @@
 package runtime
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
 	"sync"
 	"testing"
+	"time"
 )
 
 import (
+	driver "github.com/go-sql-driver/mysql"
+
 	"github.com/golang/mock/gomock"
 
 	"github.com/stretchr/testify/assert"
 )
 
 import (
+	"github.com/arana-db/arana/pkg/config"
+	consts "github.com/arana-db/arana/pkg/constants/mysql"
+	"github.com/arana-db/arana/pkg/mysql"
+	err2 "github.com/arana-db/arana/pkg/mysql/errors"
+	"github.com/arana-db/arana/pkg/proto"
 	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/security"
 	"github.com/arana-db/arana/testdata"
 )
 
@@ -70,3 +83,180 @@ func TestNextTxID(t *testing.T) {
 
 	wg.Wait()
 }
+
+// fakeBackendExecutor blocks the queries until they are killed.
+type fakeBackendExecutor struct {
+	proto.Executor
+}
+
+func (fakeBackendExecutor) ExecuteUseDB(*proto.Context) error {
+	return nil
+}
+
+func (fakeBackendExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Result, uint16, error) {
+	<-ctx.Context.Done()
+	return nil, 0, ctx.Context.Err()
+}
+
+func (fakeBackendExecutor) InLocalTransaction(*proto.Context) bool {
+	return false
+}
+
+func (fakeBackendExecutor) ConnectionClose(*proto.Context) {
+}
+
+// startFakeListener starts a frontend listener with the executor, and returns its port.
+func startFakeListener(t *testing.T, executor proto.Executor) int {
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := free.Addr().(*net.TCPAddr).Port
+	_ = free.Close()
+
+	l, err := mysql.NewListener(&config.Listener{
+		SocketAddress: &config.SocketAddress{Address: "127.0.0.1", Port: port},
+		ServerVersion: "8.0.0",
+	})
+	assert.NoError(t, err)
+	l.SetExecutor(executor)
+	go l.Listen()
+	return port
+}
+
+func newFakeBackendConnection(t *testing.T, port int, cluster string) *mysql.BackendConnection {
+	connector, err := mysql.NewConnector([]byte(fmt.Sprintf(`{"dsn": "foo:123456@tcp(127.0.0.1:%d)/%s"}`, port, cluster)))
+	assert.NoError(t, err)
+	res, err := connector.NewBackendConnection(context.Background())
+	assert.NoError(t, err)
+	return res.(*mysql.BackendConnection)
+}
+
+func TestWatchQuery(t *testing.T) {
+	const (
+		tenant  = "fake_watch_tenant"
+		cluster = "fake_watch_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	// use the frontend listener as a fake backend, which supports KILL QUERY
+	bc := newFakeBackendConnection(t, startFakeListener(t, fakeBackendExecutor{}), cluster)
+	defer bc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	stop := watchQuery(ctx, bc)
+	_, _, err := bc.ExecuteWithWarningCount("select sleep(100)", true)
+	assert.True(t, stop())
+	if assert.IsType(t, (*err2.SQLError)(nil), err) {
+		assert.Equal(t, consts.ERQueryInterrupted, err.(*err2.SQLError).Num)
+	}
+
+	// nothing is killed if the query is finished before timeout
+	stop = watchQuery(context.Background(), bc)
+	assert.True(t, stop())
+}
+
+// fakeProxyExecutor forwards the queries to backend connection, like AtomDB does.
+type fakeProxyExecutor struct {
+	fakeBackendExecutor
+	bc      *mysql.BackendConnection
+	running chan struct{}
+	errs    chan error
+}
+
+func (f *fakeProxyExecutor) ExecutorComQuery(ctx *proto.Context) (proto.Result, uint16, error) {
+	stop := watchQuery(ctx.Context, f.bc)
+	close(f.running)
+	_, _, err := f.bc.ExecuteWithWarningCount(string(ctx.Data[1:]), true)
+	stop()
+	f.errs <- err
+	return nil, 0, err
+}
+
+func TestKillQuery(t *testing.T) {
+	const (
+		tenant  = "fake_kill_tenant"
+		cluster = "fake_kill_cluster"
+	)
+	security.DefaultTenantManager().PutCluster(tenant, cluster)
+	security.DefaultTenantManager().PutUser(tenant, &config.User{Username: "foo", Password: "123456"})
+	defer security.DefaultTenantManager().RemoveCluster(tenant, cluster)
+
+	bc := newFakeBackendConnection(t, startFakeListener(t, fakeBackendExecutor{}), cluster)
+	defer bc.Close()
+	executor := &fakeProxyExecutor{
+		bc:      bc,
+		running: make(chan struct{}),
+		errs:    make(chan error, 1),
+	}
+	port := startFakeListener(t, executor)
+
+	cfg := driver.NewConfig()
+	cfg.User, cfg.Passwd, cfg.DBName = "foo", "123456", cluster
+	cfg.Net, cfg.Addr = "tcp", fmt.Sprintf("127.0.0.1:%d", port)
+	connector, err := driver.NewConnector(cfg)
+	assert.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	sleeping, err := db.Conn(ctx)
+	assert.NoError(t, err)
+	defer sleeping.Close()
+	go func() {
+		_, _ = sleeping.ExecContext(ctx, "select sleep(100)")
+	}()
+	select {
+	case <-executor.running:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query is not running")
+	}
+
+	id := int64(-1)
+	rows, err := db.QueryContext(ctx, "SHOW FULL PROCESSLIST")
+	assert.NoError(t, err)
+	for rows.Next() {
+		var (
+			pid                            int64
+			user, host, command, tenantCol string
+			schema, state, info            sql.NullString
+			seconds                        int64
+		)
+		assert.NoError(t, rows.Scan(&pid, &user, &host, &schema, &command, &seconds, &state, &info, &tenantCol))
+		if info.String == "select sleep(100)" {
+			id = pid
+		}
+	}
+	assert.NoError(t, rows.Close())
+
+	// KILL QUERY on frontend kills the query on backend connection
+	_, err = db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id))
+	assert.NoError(t, err)
+	select {
+	case err = <-executor.errs:
+		if assert.IsType(t, (*err2.SQLError)(nil), err) {
+			assert.Equal(t, consts.ERQueryInterrupted, err.(*err2.SQLError).Num)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backend query is not killed")
+	}
+}
+
+func TestExtractArgs(t *testing.T) {
+	var (
+		rt       defaultRuntime
+		bindVars = make(map[string]interface{})
+		expected []interface{}
+	)
+	for i := 1; i <= 11; i++ {
+		bindVars[fmt.Sprintf("v%d", i)] = int64(i)
+		expected = append(expected, int64(i))
+	}
+
+	// the args are in the order of parameters, 'v10' is after 'v9'
+	args := rt.extractArgs(&proto.Context{Stmt: &proto.Stmt{BindVars: bindVars}})
+	assert.Equal(t, expected, args)
+	assert.Nil(t, rt.extractArgs(&proto.Context{}))
+}
@@ -0,0 +1,122 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+)
+
+// StaticAuthenticator is the type of default authenticator, which authenticates the users in config.
+const StaticAuthenticator = "static"
+
+// ErrAccessDenied is returned by authenticators if the request is denied.
+var ErrAccessDenied = errors.New("access denied")
+
+// AuthRequest represents the authentication request of a frontend connection.
+type AuthRequest struct {
+	// Tenant is the tenant which owns the schema.
+	Tenant     string
+	Username   string
+	Schema     string
+	ClientIP   string
+	AuthMethod string
+	// ConnAttrs is the connection attributes sent by client, eg: _client_name, program_name.
+	ConnAttrs map[string]string
+}
+
+// Credential represents the credential sent by client.
+type Credential interface {
+	// Verify verifies the credential against the stored password, which is plaintext or a mysql_native_password hash.
+	Verify(stored string) bool
+	// ClearPassword returns the clear password, returns false if the auth method doesn't send it,
+	// then the client will be asked to send the clear password, and the request will be authenticated again.
+	ClearPassword() (string, bool)
+}
+
+// Authenticator authenticates the frontend connections.
+type Authenticator interface {
+	// Authenticate returns the tenant bound to the connection, returns an error if the request is denied.
+	Authenticate(ctx context.Context, req *AuthRequest, cred Credential) (string, error)
+}
+
+// AuthenticatorFactory creates an authenticator with options.
+type AuthenticatorFactory func(options map[string]string) (Authenticator, error)
+
+var (
+	_authenticatorsLock sync.RWMutex
+	_authenticators     = map[string]AuthenticatorFactory{
+		StaticAuthenticator: func(map[string]string) (Authenticator, error) {
+			return &staticAuthenticator{tm: DefaultTenantManager()}, nil
+		},
+		FileAuthenticator: newFileAuthenticator,
+		HttpAuthenticator: newHttpAuthenticator,
+	}
+)
+
+// RegisterAuthenticatorFactory registers an authenticator factory, which can be selected by the type in config.
+func RegisterAuthenticatorFactory(typ string, factory AuthenticatorFactory) {
+	_authenticatorsLock.Lock()
+	defer _authenticatorsLock.Unlock()
+	_authenticators[typ] = factory
+}
+
+// NewAuthenticator creates the authenticator of tenant, the static authenticator is used if conf is nil.
+func NewAuthenticator(conf *config.Authenticator) (Authenticator, error) {
+	typ, options := StaticAuthenticator, map[string]string(nil)
+	if conf != nil {
+		if len(conf.Type) > 0 {
+			typ = conf.Type
+		}
+		options = conf.Options
+	}
+
+	_authenticatorsLock.RLock()
+	factory, ok := _authenticators[typ]
+	_authenticatorsLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("no such authenticator '%s'", typ)
+	}
+
+	auth, err := factory(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create authenticator '%s'", typ)
+	}
+	return auth, nil
+}
+
+// staticAuthenticator authenticates the users of tenant manager.
+type staticAuthenticator struct {
+	tm TenantManager
+}
+
+func (s *staticAuthenticator) Authenticate(_ context.Context, req *AuthRequest, cred Credential) (string, error) {
+	user, ok := s.tm.GetUser(req.Tenant, req.Username)
+	if !ok || !cred.Verify(user.Password) {
+		return "", ErrAccessDenied
+	}
+	return req.Tenant, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// FileAuthenticator is the type of authenticator, which authenticates the users in a password file.
+// Each line of file is 'username:password', the password could be a mysql_native_password hash,
+// the lines starting with '#' are ignored, and the file will be reloaded once it's modified.
+const FileAuthenticator = "file"
+
+type fileAuthenticator struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	users   map[string]string
+}
+
+func newFileAuthenticator(options map[string]string) (Authenticator, error) {
+	path := options["path"]
+	if len(path) < 1 {
+		return nil, errors.New("no path specified")
+	}
+	f := &fileAuthenticator{path: path}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileAuthenticator) Authenticate(_ context.Context, req *AuthRequest, cred Credential) (string, error) {
+	if err := f.reload(); err != nil {
+		return "", err
+	}
+
+	f.mu.RLock()
+	password, ok := f.users[req.Username]
+	f.mu.RUnlock()
+
+	if !ok || !cred.Verify(password) {
+		return "", ErrAccessDenied
+	}
+	return req.Tenant, nil
+}
+
+// reload reloads the password file if it's modified.
+func (f *fileAuthenticator) reload() error {
+	stat, err := os.Stat(f.path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	f.mu.RLock()
+	modified := !stat.ModTime().Equal(f.modTime)
+	f.mu.RUnlock()
+	if !modified {
+		return nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) < 1 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 1 {
+			return errors.Errorf("invalid line '%s' of password file %s", line, f.path)
+		}
+		users[line[:i]] = line[i+1:]
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	f.mu.Lock()
+	f.users, f.modTime = users, stat.ModTime()
+	f.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// HttpAuthenticator is the type of authenticator, which delegates the authentication to an http callback,
+// eg: a SSO token service. The clients must send the clear password by mysql_clear_password over TLS,
+// or by caching_sha2_password/sha256_password over TLS or RSA.
+//
+// The callback receives a POST request with JSON body:
+//
+//	{"tenant":"arana","username":"foo","password":"<token>","schema":"employees","client_ip":"127.0.0.1","auth_method":"mysql_clear_password","conn_attrs":{}}
+//
+// And it should respond status 200 with JSON body, the tenant could be omitted:
+//
+//	{"allow":true,"tenant":"arana"}
+const HttpAuthenticator = "http"
+
+const _defaultHttpAuthTimeout = 3 * time.Second
+
+type httpAuthRequest struct {
+	Tenant     string            `json:"tenant"`
+	Username   string            `json:"username"`
+	Password   string            `json:"password"`
+	Schema     string            `json:"schema"`
+	ClientIP   string            `json:"client_ip"`
+	AuthMethod string            `json:"auth_method"`
+	ConnAttrs  map[string]string `json:"conn_attrs"`
+}
+
+type httpAuthResponse struct {
+	Allow  bool   `json:"allow"`
+	Tenant string `json:"tenant"`
+}
+
+type httpAuthenticator struct {
+	url    string
+	client *http.Client
+}
+
+func newHttpAuthenticator(options map[string]string) (Authenticator, error) {
+	url := options["url"]
+	if len(url) < 1 {
+		return nil, errors.New("no url specified")
+	}
+
+	timeout := _defaultHttpAuthTimeout
+	if s, ok := options["timeout"]; ok {
+		var err error
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return nil, errors.Wrapf(err, "invalid timeout '%s'", s)
+		}
+	}
+
+	return &httpAuthenticator{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (h *httpAuthenticator) Authenticate(ctx context.Context, req *AuthRequest, cred Credential) (string, error) {
+	password, ok := cred.ClearPassword()
+	if !ok {
+		return "", ErrAccessDenied
+	}
+
+	body, err := json.Marshal(&httpAuthRequest{
+		Tenant:     req.Tenant,
+		Username:   req.Username,
+		Password:   password,
+		Schema:     req.Schema,
+		ClientIP:   req.ClientIP,
+		AuthMethod: req.AuthMethod,
+		ConnAttrs:  req.ConnAttrs,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(r)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to call auth callback")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("auth callback responds %s", resp.Status)
+	}
+
+	var result httpAuthResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.Wrap(err, "failed to decode auth callback response")
+	}
+	if !result.Allow {
+		return "", ErrAccessDenied
+	}
+	if len(result.Tenant) > 0 {
+		return result.Tenant, nil
+	}
+	return req.Tenant, nil
+}
@@ -0,0 +1,164 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/config"
+)
+
+type fakeCredential struct {
+	password string
+	clear    bool
+}
+
+func (f fakeCredential) Verify(stored string) bool {
+	return f.password == stored
+}
+
+func (f fakeCredential) ClearPassword() (string, bool) {
+	return f.password, f.clear
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	auth, err := NewAuthenticator(nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &staticAuthenticator{}, auth)
+
+	_, err = NewAuthenticator(&config.Authenticator{Type: "fake"})
+	assert.Error(t, err)
+	_, err = NewAuthenticator(&config.Authenticator{Type: FileAuthenticator})
+	assert.Error(t, err)
+	_, err = NewAuthenticator(&config.Authenticator{Type: HttpAuthenticator, Options: map[string]string{"url": "http://127.0.0.1", "timeout": "x"}})
+	assert.Error(t, err)
+
+	RegisterAuthenticatorFactory("fake", func(map[string]string) (Authenticator, error) {
+		return &staticAuthenticator{tm: newSimpleTenantManager()}, nil
+	})
+	_, err = NewAuthenticator(&config.Authenticator{Type: "fake"})
+	assert.NoError(t, err)
+}
+
+func TestStaticAuthenticator(t *testing.T) {
+	tm := newSimpleTenantManager()
+	tm.PutUser("fake-tenant", &config.User{Username: "foo", Password: "123456"})
+	auth := &staticAuthenticator{tm: tm}
+
+	req := &AuthRequest{Tenant: "fake-tenant", Username: "foo"}
+	tenant, err := auth.Authenticate(context.Background(), req, fakeCredential{password: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-tenant", tenant)
+
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "654321"})
+	assert.Equal(t, ErrAccessDenied, err)
+	_, err = auth.Authenticate(context.Background(), &AuthRequest{Tenant: "fake-tenant", Username: "bar"}, fakeCredential{})
+	assert.Equal(t, ErrAccessDenied, err)
+}
+
+func TestFileAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwd")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("# users\nfoo:123456\n\nbar:a:b\n"), 0o600))
+
+	auth, err := NewAuthenticator(&config.Authenticator{Type: FileAuthenticator, Options: map[string]string{"path": path}})
+	assert.NoError(t, err)
+
+	req := &AuthRequest{Tenant: "fake-tenant", Username: "foo"}
+	tenant, err := auth.Authenticate(context.Background(), req, fakeCredential{password: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-tenant", tenant)
+	_, err = auth.Authenticate(context.Background(), &AuthRequest{Username: "bar"}, fakeCredential{password: "a:b"})
+	assert.NoError(t, err)
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "654321"})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	// the file is reloaded once modified
+	assert.NoError(t, ioutil.WriteFile(path, []byte("foo:654321\n"), 0o600))
+	modTime := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "654321"})
+	assert.NoError(t, err)
+	_, err = auth.Authenticate(context.Background(), &AuthRequest{Username: "bar"}, fakeCredential{password: "a:b"})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("invalid\n"), 0o600))
+	modTime = modTime.Add(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "654321"})
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrAccessDenied, err)
+}
+
+func TestHttpAuthenticator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req httpAuthRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.Password {
+		case "token":
+			_ = json.NewEncoder(w).Encode(&httpAuthResponse{Allow: true})
+		case "bound":
+			_ = json.NewEncoder(w).Encode(&httpAuthResponse{Allow: true, Tenant: "other-tenant"})
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_ = json.NewEncoder(w).Encode(&httpAuthResponse{Allow: false})
+		}
+	}))
+	defer server.Close()
+
+	auth, err := NewAuthenticator(&config.Authenticator{Type: HttpAuthenticator, Options: map[string]string{"url": server.URL}})
+	assert.NoError(t, err)
+
+	req := &AuthRequest{Tenant: "fake-tenant", Username: "foo", ClientIP: "127.0.0.1"}
+	tenant, err := auth.Authenticate(context.Background(), req, fakeCredential{password: "token", clear: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-tenant", tenant)
+
+	tenant, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "bound", clear: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "other-tenant", tenant)
+
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "wrong", clear: true})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	// the clear password is required
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "token"})
+	assert.Equal(t, ErrAccessDenied, err)
+
+	_, err = auth.Authenticate(context.Background(), req, fakeCredential{password: "broken", clear: true})
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrAccessDenied, err)
+}
@@ -19,6 +19,7 @@ package security
 
 import (
 	"sync"
+	"time"
 )
 
 import (
@@ -43,11 +44,28 @@ type TenantManager interface {
 	PutCluster(tenant string, cluster string)
 	// RemoveCluster removes a cluster from tenant.
 	RemoveCluster(tenant string, cluster string)
+	// SetRequireTLS sets whether all users of tenant must connect with TLS.
+	SetRequireTLS(tenant string, require bool)
+	// IsRequireTLS returns true if all users of tenant must connect with TLS.
+	IsRequireTLS(tenant string) bool
+	// SetAuthenticator sets the authenticator of tenant, the static authenticator is used if nil.
+	SetAuthenticator(tenant string, auth Authenticator)
+	// GetAuthenticator returns the authenticator of tenant.
+	GetAuthenticator(tenant string) Authenticator
+	// SetMaxExecutionTime sets the default timeout of statements of tenant, zero means unlimited.
+	SetMaxExecutionTime(tenant string, timeout time.Duration)
+	// GetMaxExecutionTime returns the default timeout of statements of tenant.
+	GetMaxExecutionTime(tenant string) time.Duration
+	// RemoveTenant removes the tenant with its users, clusters and settings.
+	RemoveTenant(tenant string)
 }
 
 type tenantItem struct {
-	clusters map[string]struct{}
-	users    map[string]*config.User
+	clusters         map[string]struct{}
+	users            map[string]*config.User
+	requireTLS       bool
+	authenticator    Authenticator
+	maxExecutionTime time.Duration
 }
 
 type simpleTenantManager struct {
@@ -95,6 +113,11 @@ func (st *simpleTenantManager) GetTenantOfCluster(cluster string) (string, bool)
 func (st *simpleTenantManager) PutUser(tenant string, user *config.User) {
 	st.Lock()
 	defer st.Unlock()
+	st.getOrCreate(tenant).users[user.Username] = user
+}
+
+// getOrCreate returns the tenant, which is created if not exists, the caller must hold the write lock.
+func (st *simpleTenantManager) getOrCreate(tenant string) *tenantItem {
 	current, ok := st.tenants[tenant]
 	if !ok {
 		current = &tenantItem{
@@ -103,7 +126,7 @@ func (st *simpleTenantManager) PutUser(tenant string, user *config.User) {
 		}
 		st.tenants[tenant] = current
 	}
-	current.users[user.Username] = user
+	return current
 }
 
 func (st *simpleTenantManager) RemoveUser(tenant string, username string) {
@@ -122,16 +145,7 @@ func (st *simpleTenantManager) PutCluster(tenant string, cluster string) {
 	st.Lock()
 	defer st.Unlock()
 
-	current, ok := st.tenants[tenant]
-	if !ok {
-		current = &tenantItem{
-			clusters: make(map[string]struct{}),
-			users:    make(map[string]*config.User),
-		}
-		st.tenants[tenant] = current
-	}
-
-	current.clusters[cluster] = struct{}{}
+	st.getOrCreate(tenant).clusters[cluster] = struct{}{}
 }
 
 func (st *simpleTenantManager) RemoveCluster(tenant string, cluster string) {
@@ -145,6 +159,42 @@ func (st *simpleTenantManager) RemoveCluster(tenant string, cluster string) {
 	delete(exist.clusters, cluster)
 }
 
+func (st *simpleTenantManager) RemoveTenant(tenant string) {
+	st.Lock()
+	defer st.Unlock()
+	delete(st.tenants, tenant)
+}
+
+func (st *simpleTenantManager) SetRequireTLS(tenant string, require bool) {
+	st.Lock()
+	defer st.Unlock()
+
+	st.getOrCreate(tenant).requireTLS = require
+}
+
+func (st *simpleTenantManager) IsRequireTLS(tenant string) bool {
+	st.RLock()
+	defer st.RUnlock()
+	exist, ok := st.tenants[tenant]
+	return ok && exist.requireTLS
+}
+
+func (st *simpleTenantManager) SetAuthenticator(tenant string, auth Authenticator) {
+	st.Lock()
+	defer st.Unlock()
+
+	st.getOrCreate(tenant).authenticator = auth
+}
+
+func (st *simpleTenantManager) GetAuthenticator(tenant string) Authenticator {
+	st.RLock()
+	defer st.RUnlock()
+	if exist, ok := st.tenants[tenant]; ok && exist.authenticator != nil {
+		return exist.authenticator
+	}
+	return &staticAuthenticator{tm: st}
+}
+
 var (
 	_defaultTenantManager     TenantManager
 	_defaultTenantManagerOnce sync.Once
@@ -162,3 +212,19 @@ func DefaultTenantManager() TenantManager {
 	})
 	return _defaultTenantManager
 }
+
+func (st *simpleTenantManager) SetMaxExecutionTime(tenant string, timeout time.Duration) {
+	st.Lock()
+	defer st.Unlock()
+
+	st.getOrCreate(tenant).maxExecutionTime = timeout
+}
+
+func (st *simpleTenantManager) GetMaxExecutionTime(tenant string) time.Duration {
+	st.RLock()
+	defer st.RUnlock()
+	if exist, ok := st.tenants[tenant]; ok {
+		return exist.maxExecutionTime
+	}
+	return 0
+}
@@ -19,6 +19,7 @@ package security
 
 import (
 	"testing"
+	"time"
 )
 
 import (
@@ -66,6 +67,23 @@ func TestSimpleTenantManager(t *testing.T) {
 	assert.Len(t, clusters, 1)
 	assert.Equal(t, []string{"fake-cluster"}, clusters)
 
+	assert.False(t, tm.IsRequireTLS("fake-tenant"))
+	tm.SetRequireTLS("fake-tenant", true)
+	assert.True(t, tm.IsRequireTLS("fake-tenant"))
+	assert.False(t, tm.IsRequireTLS("other-tenant"))
+
+	assert.Zero(t, tm.GetMaxExecutionTime("fake-tenant"))
+	tm.SetMaxExecutionTime("fake-tenant", time.Second)
+	assert.Equal(t, time.Second, tm.GetMaxExecutionTime("fake-tenant"))
+	assert.Zero(t, tm.GetMaxExecutionTime("other-tenant"))
+
 	tm.RemoveUser("fake-tenant", "fake-user")
 	tm.RemoveCluster("fake-tenant", "fake-cluster")
+
+	tm.PutUser("fake-tenant", &config.User{Username: "fake-user"})
+	tm.RemoveTenant("fake-tenant")
+	_, ok = tm.GetUser("fake-tenant", "fake-user")
+	assert.False(t, ok)
+	assert.False(t, tm.IsRequireTLS("fake-tenant"))
+	assert.Zero(t, tm.GetMaxExecutionTime("fake-tenant"))
 }
@@ -17,8 +17,16 @@
 
 package server
 
+import (
+	"context"
+	"sync"
+)
+
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/runtime"
+	"github.com/arana-db/arana/pkg/runtime/namespace"
+	"github.com/arana-db/arana/pkg/util/log"
 )
 
 type Server struct {
@@ -40,3 +48,28 @@ func (srv *Server) Start() {
 		go l.Listen()
 	}
 }
+
+// Shutdown shuts down the server gracefully:
+//  1. stop accepting new connections, and reject new transactions
+//  2. wait for the in-flight statements until ctx is done, the remaining statements will be killed
+//  3. roll back the open transactions, which is done by closing the connections
+//  4. close all namespaces and connection pools
+func (srv *Server) Shutdown(ctx context.Context) {
+	runtime.Drain()
+
+	var wg sync.WaitGroup
+	for _, l := range srv.listeners {
+		wg.Add(1)
+		go func(l proto.Listener) {
+			defer wg.Done()
+			l.Shutdown(ctx)
+		}(l)
+	}
+	wg.Wait()
+
+	for _, name := range namespace.List() {
+		if err := namespace.Unregister(name); err != nil {
+			log.Errorf("failed to close namespace %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package slowlog records the queries which are slower than the threshold of tenant into a separate rotated file.
+package slowlog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/natefinch/lumberjack"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const _defaultPath = "logs/slow.log"
+
+// Options represents the options of slow log file.
+type Options struct {
+	// Path is the file path of slow log, default is logs/slow.log.
+	Path string `yaml:"path" json:"path"`
+	// MaxSize is the max size in megabytes of a slow log file before it gets rotated, default is 10.
+	MaxSize int `yaml:"max_size" json:"max_size"`
+	// MaxBackups is the max number of old slow log files to retain, default is 5.
+	MaxBackups int `yaml:"max_backups" json:"max_backups"`
+	// MaxAge is the max days to retain old slow log files, default is 30.
+	MaxAge int `yaml:"max_age" json:"max_age"`
+}
+
+var (
+	_logger     *zap.Logger
+	_loggerOnce sync.Once
+
+	_thresholds sync.Map // tenant -> time.Duration
+)
+
+// Init initializes the slow log file, the default options will be used if Init is never called.
+func Init(opts *Options) {
+	_loggerOnce.Do(func() {
+		_logger = newLogger(opts)
+	})
+}
+
+func newLogger(opts *Options) *zap.Logger {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Path) < 1 {
+		o.Path = _defaultPath
+	}
+	if o.MaxSize < 1 {
+		o.MaxSize = 10
+	}
+	if o.MaxBackups < 1 {
+		o.MaxBackups = 5
+	}
+	if o.MaxAge < 1 {
+		o.MaxAge = 30
+	}
+
+	syncer := zapcore.AddSync(&lumberjack.Logger{
+		Filename:   o.Path,
+		MaxSize:    o.MaxSize,
+		MaxBackups: o.MaxBackups,
+		MaxAge:     o.MaxAge,
+	})
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.LevelKey = ""
+	encoderConfig.CallerKey = ""
+
+	return zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), syncer, zapcore.InfoLevel))
+}
+
+func logger() *zap.Logger {
+	Init(nil)
+	return _logger
+}
+
+// SetThreshold sets the slow query threshold of tenant, the slow log of tenant is disabled if threshold is not positive.
+func SetThreshold(tenant string, threshold time.Duration) {
+	if threshold <= 0 {
+		_thresholds.Delete(tenant)
+		return
+	}
+	_thresholds.Store(tenant, threshold)
+}
+
+// Threshold returns the slow query threshold of tenant.
+func Threshold(tenant string) (time.Duration, bool) {
+	if v, ok := _thresholds.Load(tenant); ok {
+		return v.(time.Duration), true
+	}
+	return 0, false
+}
+
+// Call represents a physical sql executed on a backend node.
+type Call struct {
+	Group   string  `json:"group"`
+	Node    string  `json:"node"`
+	SQL     string  `json:"sql"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Entry collects the details of a query, which will be written if the query is slower than threshold.
+// All methods are safe to call on a nil Entry.
+type Entry struct {
+	mu sync.Mutex
+
+	threshold  time.Duration
+	start      time.Time
+	sql        string
+	tenant     string
+	user       string
+	clientAddr string
+	optimize   time.Duration
+	fullScan   bool
+	calls      []Call
+}
+
+type entryKey struct{}
+
+// Begin begins a new entry if the slow log of tenant is enabled.
+func Begin(ctx context.Context, tenant, user, clientAddr, sql string) context.Context {
+	threshold, ok := Threshold(tenant)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, entryKey{}, &Entry{
+		threshold:  threshold,
+		start:      time.Now(),
+		sql:        sql,
+		tenant:     tenant,
+		user:       user,
+		clientAddr: clientAddr,
+	})
+}
+
+// FromContext returns the entry of current query, returns nil if the slow log is disabled.
+func FromContext(ctx context.Context) *Entry {
+	e, _ := ctx.Value(entryKey{}).(*Entry)
+	return e
+}
+
+// SetOptimize sets the elapsed time of optimizing.
+func (e *Entry) SetOptimize(elapsed time.Duration) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.optimize = elapsed
+	e.mu.Unlock()
+}
+
+// MarkFullScan marks the query as a full-scan query.
+func (e *Entry) MarkFullScan() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.fullScan = true
+	e.mu.Unlock()
+}
+
+// AddCall adds a physical sql executed on backend node.
+func (e *Entry) AddCall(group, node, sql string, elapsed time.Duration, err error) {
+	if e == nil {
+		return
+	}
+	call := Call{
+		Group:   group,
+		Node:    node,
+		SQL:     sql,
+		Latency: toMillis(elapsed),
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	e.mu.Lock()
+	e.calls = append(e.calls, call)
+	e.mu.Unlock()
+}
+
+// Finish finishes the entry, it will be written into slow log if the query is slower than threshold.
+func (e *Entry) Finish(rows int, err error) {
+	if e == nil {
+		return
+	}
+
+	total := time.Since(e.start)
+	if total < e.threshold {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fields := []zap.Field{
+		zap.String("sql", e.sql),
+		zap.String("tenant", e.tenant),
+		zap.String("user", e.user),
+		zap.String("client", e.clientAddr),
+		zap.Float64("total_ms", toMillis(total)),
+		zap.Float64("optimize_ms", toMillis(e.optimize)),
+		zap.Int("rows", rows),
+		zap.Bool("full_scan", e.fullScan),
+		zap.Any("calls", e.calls),
+	}
+	if err != nil {
+		fields = append(fields, zap.String("error", err.Error()))
+	}
+	logger().Info("slow query", fields...)
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
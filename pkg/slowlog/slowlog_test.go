@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package slowlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.log")
+	Init(&Options{Path: path})
+
+	// disabled tenant
+	ctx := Begin(context.Background(), "fake_tenant", "arana", "127.0.0.1:3306", "select 1")
+	assert.Nil(t, FromContext(ctx))
+	FromContext(ctx).AddCall("employees_0000", "node0", "select 1", time.Millisecond, nil)
+	FromContext(ctx).Finish(1, nil)
+
+	SetThreshold("arana", time.Hour)
+	ctx = Begin(context.Background(), "arana", "arana", "127.0.0.1:3306", "select 1")
+	assert.NotNil(t, FromContext(ctx))
+	FromContext(ctx).Finish(1, nil) // faster than threshold
+
+	SetThreshold("arana", time.Nanosecond)
+	defer SetThreshold("arana", 0)
+
+	ctx = Begin(context.Background(), "arana", "dksl", "127.0.0.1:3306", "select * from student where uid > 1")
+	entry := FromContext(ctx)
+	entry.SetOptimize(2 * time.Millisecond)
+	entry.MarkFullScan()
+	entry.AddCall("employees_0000", "node0", "select * from student_0000 where uid > 1", 3*time.Millisecond, nil)
+	entry.AddCall("employees_0000", "node0", "select * from student_0001 where uid > 1", 4*time.Millisecond, errors.New("oops"))
+	entry.Finish(2, nil)
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 1)
+
+	var record struct {
+		SQL      string  `json:"sql"`
+		Tenant   string  `json:"tenant"`
+		User     string  `json:"user"`
+		Client   string  `json:"client"`
+		Total    float64 `json:"total_ms"`
+		Optimize float64 `json:"optimize_ms"`
+		Rows     int     `json:"rows"`
+		FullScan bool    `json:"full_scan"`
+		Calls    []Call  `json:"calls"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "select * from student where uid > 1", record.SQL)
+	assert.Equal(t, "arana", record.Tenant)
+	assert.Equal(t, "dksl", record.User)
+	assert.Equal(t, "127.0.0.1:3306", record.Client)
+	assert.Equal(t, 2.0, record.Optimize)
+	assert.Equal(t, 2, record.Rows)
+	assert.True(t, record.FullScan)
+	assert.Len(t, record.Calls, 2)
+	assert.Equal(t, 4.0, record.Calls[1].Latency)
+	assert.Equal(t, "oops", record.Calls[1].Error)
+}
@@ -0,0 +1,65 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+import (
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const _traceParent = "traceparent"
+
+var _commentRegexp = regexp.MustCompile(`/\*(.*?)\*/`)
+
+// Extract extracts the trace context from the sql comment in sqlcommenter format, eg:
+//
+//	SELECT * FROM student /*traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/
+//
+// The original context will be returned if no trace context found.
+func Extract(ctx context.Context, sql string) context.Context {
+	if !strings.Contains(sql, _traceParent) {
+		return ctx
+	}
+
+	carrier := make(propagation.MapCarrier)
+	for _, match := range _commentRegexp.FindAllStringSubmatch(sql, -1) {
+		for _, pair := range strings.Split(match[1], ",") {
+			i := strings.IndexByte(pair, '=')
+			if i < 0 {
+				continue
+			}
+			key := strings.TrimSpace(pair[:i])
+			value := strings.Trim(strings.TrimSpace(pair[i+1:]), "'\"")
+			if unescaped, err := url.QueryUnescape(value); err == nil {
+				value = unescaped
+			}
+			carrier.Set(key, value)
+		}
+	}
+
+	if len(carrier.Get(_traceParent)) < 1 {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
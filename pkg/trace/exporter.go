@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ sdktrace.SpanExporter = (*fileExporter)(nil)
+
+// spanRecord is the JSON line written by file exporter.
+type spanRecord struct {
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Name         string                 `json:"name"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Duration     string                 `json:"duration"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Status       string                 `json:"status"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// fileExporter writes spans into a local file as JSON lines, which is useful for testing.
+type fileExporter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileExporter creates a span exporter which writes spans into given file as JSON lines.
+func NewFileExporter(path string) (sdktrace.SpanExporter, error) {
+	if len(path) < 1 {
+		return nil, errors.New("no path specified for file trace exporter")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open trace file %s", path)
+	}
+	return &fileExporter{f: f}, nil
+}
+
+func (fe *fileExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	if fe.f == nil {
+		return nil
+	}
+
+	enc := json.NewEncoder(fe.f)
+	for _, span := range spans {
+		record := spanRecord{
+			TraceID:  span.SpanContext().TraceID().String(),
+			SpanID:   span.SpanContext().SpanID().String(),
+			Name:     span.Name(),
+			Start:    span.StartTime(),
+			End:      span.EndTime(),
+			Duration: span.EndTime().Sub(span.StartTime()).String(),
+			Status:   span.Status().Code.String(),
+			Error:    span.Status().Description,
+		}
+		if span.Parent().IsValid() {
+			record.ParentSpanID = span.Parent().SpanID().String()
+		}
+		if attrs := span.Attributes(); len(attrs) > 0 {
+			record.Attributes = make(map[string]interface{}, len(attrs))
+			for _, it := range attrs {
+				record.Attributes[string(it.Key)] = it.Value.AsInterface()
+			}
+		}
+		if err := enc.Encode(&record); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (fe *fileExporter) Shutdown(_ context.Context) error {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	if fe.f == nil {
+		return nil
+	}
+	err := fe.f.Close()
+	fe.f = nil
+	return errors.WithStack(err)
+}
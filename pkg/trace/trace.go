@@ -0,0 +1,134 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace provides the OpenTelemetry tracing of arana.
+// Nothing will be recorded until the tracing is initialized by Init.
+package trace
+
+import (
+	"context"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const _instrumentation = "github.com/arana-db/arana"
+
+const (
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+// Span attribute keys.
+const (
+	KeyConnectionID = attribute.Key("arana.connection_id")
+	KeyTenant       = attribute.Key("arana.tenant")
+	KeySchema       = attribute.Key("arana.schema")
+	KeyGroup        = attribute.Key("arana.db.group")
+	KeyNode         = attribute.Key("arana.db.node")
+	KeySQL          = attribute.Key("db.statement")
+)
+
+// Options represents the options of tracing.
+type Options struct {
+	// Exporter is the exporter of spans, which could be 'otlp' or 'file', the tracing is disabled if empty.
+	Exporter string `yaml:"exporter" json:"exporter"`
+	// Endpoint is the OTLP/HTTP endpoint, eg: 127.0.0.1:4318.
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	// Insecure disables the TLS of OTLP/HTTP exporter.
+	Insecure bool `yaml:"insecure" json:"insecure"`
+	// Path is the output file of file exporter.
+	Path string `yaml:"path" json:"path"`
+	// SampleRatio is the ratio of sampled traces, default is 1.
+	SampleRatio *float64 `yaml:"sample_ratio" json:"sample_ratio"`
+	// ServiceName is the service name of spans, default is arana.
+	ServiceName string `yaml:"service_name" json:"service_name"`
+}
+
+// Init initializes the global tracing with given options, the returned function should be called to flush the spans before exit.
+func Init(ctx context.Context, opts *Options) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if opts == nil || len(opts.Exporter) < 1 {
+		return noop, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch strings.ToLower(opts.Exporter) {
+	case ExporterOTLP:
+		httpOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		if exporter, err = otlptracehttp.New(ctx, httpOpts...); err != nil {
+			return noop, errors.Wrap(err, "failed to create otlp trace exporter")
+		}
+	case ExporterFile:
+		if exporter, err = NewFileExporter(opts.Path); err != nil {
+			return noop, err
+		}
+	default:
+		return noop, errors.Errorf("unknown trace exporter '%s'", opts.Exporter)
+	}
+
+	ratio := 1.0
+	if opts.SampleRatio != nil {
+		ratio = *opts.SampleRatio
+	}
+	serviceName := opts.ServiceName
+	if len(serviceName) < 1 {
+		serviceName = "arana"
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a new span, the span should be ended by End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, oteltrace.Span) {
+	return otel.Tracer(_instrumentation).Start(ctx, name, oteltrace.WithAttributes(attrs...))
+}
+
+// End ends the span, the error will be recorded if exists.
+func End(span oteltrace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	_traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	_spanID  = "00f067aa0ba902b7"
+)
+
+func TestExtract(t *testing.T) {
+	for _, sql := range []string{
+		"select * from student /*traceparent='00-" + _traceID + "-" + _spanID + "-01'*/",
+		"/* traceparent = '00-" + _traceID + "-" + _spanID + "-01', tracestate='foo%3Dbar' */ select 1",
+	} {
+		sc := oteltrace.SpanContextFromContext(Extract(context.Background(), sql))
+		assert.True(t, sc.IsValid(), sql)
+		assert.True(t, sc.IsRemote())
+		assert.Equal(t, _traceID, sc.TraceID().String())
+		assert.Equal(t, _spanID, sc.SpanID().String())
+	}
+
+	for _, sql := range []string{
+		"select 1",
+		"select 1 /* traceparent */",
+		"select 1 /*traceparent='illegal'*/",
+	} {
+		sc := oteltrace.SpanContextFromContext(Extract(context.Background(), sql))
+		assert.False(t, sc.IsValid(), sql)
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+
+	shutdown, err := Init(context.Background(), &Options{Exporter: ExporterFile, Path: path})
+	assert.NoError(t, err)
+
+	ctx := Extract(context.Background(), "select 1 /*traceparent='00-"+_traceID+"-"+_spanID+"-01'*/")
+	ctx, parent := Start(ctx, "COM_QUERY", KeyTenant.String("arana"))
+	_, child := Start(ctx, "AtomDB.Call", KeyNode.String("node0"), KeySQL.String("select 1"))
+	End(child, errors.New("oops"))
+	End(parent, nil)
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	records := make(map[string]spanRecord)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record spanRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records[record.Name] = record
+	}
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, _traceID, records["COM_QUERY"].TraceID)
+	assert.Equal(t, _spanID, records["COM_QUERY"].ParentSpanID)
+	assert.Equal(t, "arana", records["COM_QUERY"].Attributes[string(KeyTenant)])
+	assert.Equal(t, records["COM_QUERY"].SpanID, records["AtomDB.Call"].ParentSpanID)
+	assert.Equal(t, "select 1", records["AtomDB.Call"].Attributes[string(KeySQL)])
+	assert.Equal(t, "Error", records["AtomDB.Call"].Status)
+	assert.Equal(t, "oops", records["AtomDB.Call"].Error)
+
+	_, err = Init(context.Background(), &Options{Exporter: "unknown"})
+	assert.Error(t, err)
+}
@@ -128,3 +128,13 @@ func writeTable(w io.Writer, rows *sql.Rows, color bool) ([][]string, error) {
 
 	return converts, nil
 }
+
+// WriteRows writes string rows into writer as table format.
+func WriteRows(w io.Writer, header []string, rows [][]string) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+}